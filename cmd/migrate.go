@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+
+	l "log"
+
+	"user-management/migrations"
+	"user-management/pkg/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+// migrationNameRegexp matches names which can be used in migration file names.
+var migrationNameRegexp = regexp.MustCompile(`^\w+$`)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database schema",
+	Long: `Migrate the database schema with migrations embedded into the binary.
+The current version is kept in the schema_migrations table and an advisory lock is held while migrating,
+so instances started at the same time do not race.`,
+}
+
+// migrateUpCmd represents the command that applies migrations
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "Apply all or N next migrations",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		steps := parseSteps(args)
+		ctx := cmd.Context()
+		migrator := loadMigrator(ctx)
+		defer postgresClient.Close(ctx)
+
+		applied, err := migrator.Up(ctx, steps)
+		printMigrations("applied", applied)
+		if err != nil {
+			l.Fatalf("unable to migrate up: %v", err)
+		}
+	},
+}
+
+// migrateDownCmd represents the command that reverts migrations
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Revert the last or N last migrations",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// reverting everything must be explicit, so down reverts one migration by default.
+		steps := 1
+		if len(args) > 0 {
+			steps = parseSteps(args)
+		}
+
+		ctx := cmd.Context()
+		migrator := loadMigrator(ctx)
+		defer postgresClient.Close(ctx)
+
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("reverted", reverted)
+		if err != nil {
+			l.Fatalf("unable to migrate down: %v", err)
+		}
+	},
+}
+
+// migrateGotoCmd represents the command that migrates up or down to a version
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto N",
+	Short: "Migrate up or down to version N, 0 reverts all migrations",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			l.Fatalf("version %q is not valid", args[0])
+		}
+
+		ctx := cmd.Context()
+		migrator := loadMigrator(ctx)
+		defer postgresClient.Close(ctx)
+
+		migrated, err := migrator.Goto(ctx, version)
+		printMigrations("migrated", migrated)
+		if err != nil {
+			l.Fatalf("unable to migrate to version %d: %v", version, err)
+		}
+	},
+}
+
+// migrateStatusCmd represents the command that prints migrations and whether they were applied
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the current version and status of all migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		migrator := loadMigrator(ctx)
+		defer postgresClient.Close(ctx)
+
+		statuses, version, err := migrator.Status(ctx)
+		if err != nil {
+			l.Fatalf("unable to get migration status: %v", err)
+		}
+
+		fmt.Printf("current version: %d\n", version)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%05d\t%s\t%s\n", status.Version, status.Name, state)
+		}
+		w.Flush()
+	},
+}
+
+// migrateCreateCmd represents the command that creates files of a new migration
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create up and down files of a new migration",
+	Long: `Create up and down files of a new migration in the migrations directory of the source tree,
+the binary has to be rebuilt to embed them.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !migrationNameRegexp.MatchString(args[0]) {
+			l.Fatalf("name %q is not valid, only letters, digits and underscores are allowed", args[0])
+		}
+
+		dir, _ := cmd.Flags().GetString("dir")
+		existed, err := migrate.Load(os.DirFS(dir))
+		if err != nil {
+			l.Fatalf("unable to load migrations: %v", err)
+		}
+
+		up, down := migrate.FileNames(existed, args[0])
+		for _, name := range []string{up, down} {
+			path := filepath.Join(dir, name)
+			// O_EXCL never overwrites an existing migration.
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+			if err != nil {
+				l.Fatalf("unable to create migration file: %v", err)
+			}
+			f.Close()
+
+			l.Printf("created %s", path)
+		}
+	},
+}
+
+// loadMigrator returns a migrator with embedded migrations connected to the configured postgres.
+func loadMigrator(ctx context.Context) *migrate.Migrator {
+	loadConfigs()
+	loadPostgresClient()
+	if err := postgresClient.Connect(ctx); err != nil {
+		l.Fatalf("unable to connect postgres: %v", err)
+	}
+
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		l.Fatalf("unable to load migrations: %v", err)
+	}
+
+	return migrate.New(postgresClient.DB, all)
+}
+
+// parseSteps returns the number of steps in args, 0 means all.
+func parseSteps(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 {
+		l.Fatalf("number of migrations %q is not valid", args[0])
+	}
+
+	return steps
+}
+
+func printMigrations(action string, list []*migrate.Migration) {
+	for _, migration := range list {
+		l.Printf("%s %05d_%s", action, migration.Version, migration.Name)
+	}
+
+	if len(list) == 0 {
+		l.Println("no change")
+	}
+}
+
+func init() {
+	migrateCreateCmd.Flags().String("dir", "migrations", "directory of migration files")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateGotoCmd, migrateStatusCmd, migrateCreateCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	l "log"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/pkg/currency"
+	"user-management/pkg/database"
+
+	"github.com/spf13/cobra"
+)
+
+// ratesCmd represents the exchange rates command
+var ratesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "Manage exchange rates used for currency conversion",
+}
+
+// ratesLoadCmd represents the command that loads exchange rates from a csv file
+var ratesLoadCmd = &cobra.Command{
+	Use:   "load FILE",
+	Short: "Load exchange rates from a csv file",
+	Long: `Load exchange rates from a csv file into the exchange_rates table.
+Each line is "base,quote,rate" where 1 major unit of base equals rate major units of quote,
+existing pairs are replaced. For example:
+
+USD,JPY,149.50
+EUR,USD,1.0875`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		loadConfigs()
+		loadPostgresClient()
+		if err := postgresClient.Connect(ctx); err != nil {
+			l.Fatalf("unable to connect postgres: %v", err)
+		}
+		defer postgresClient.Close(ctx)
+
+		rates, err := readExchangeRates(args[0])
+		if err != nil {
+			l.Fatalf("unable to read exchange rates: %v", err)
+		}
+
+		repo := repositories.NewExchangeRateRepository()
+		if err := postgresClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+			for _, rate := range rates {
+				if err := repo.Upsert(ctx, tx, rate); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			l.Fatalf("unable to load exchange rates: %v", err)
+		}
+
+		l.Printf("loaded %d exchange rates", len(rates))
+	},
+}
+
+// readExchangeRates returns the exchange rates from csv file, a header line is skipped if exists.
+func readExchangeRates(path string) ([]*entities.ExchangeRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var result []*entities.ExchangeRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "base") {
+			continue
+		}
+
+		base, quote := currency.Normalize(record[0]), currency.Normalize(record[1])
+		if !currency.IsValid(base) || !currency.IsValid(quote) {
+			return nil, fmt.Errorf("line %d: currency is not supported", line)
+		}
+
+		if _, err := currency.ParseRate(record[2]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		result = append(result, &entities.ExchangeRate{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Rate:          strings.TrimSpace(record[2]),
+		})
+	}
+
+	return result, nil
+}
+
+func init() {
+	ratesCmd.AddCommand(ratesLoadCmd)
+	rootCmd.AddCommand(ratesCmd)
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"math/rand"
+	"net/http"
 	"os"
 	"time"
 
@@ -17,34 +19,75 @@ import (
 	"user-management/pkg/cache"
 	"user-management/pkg/crypto_utils"
 	"user-management/pkg/database"
+	"user-management/pkg/events"
+	"user-management/pkg/health"
 	"user-management/pkg/http_server"
 	"user-management/pkg/http_server/xcontext"
 	"user-management/pkg/id_utils"
+	"user-management/pkg/invalidation"
+	"user-management/pkg/lockout"
 	log "user-management/pkg/logger"
 	"user-management/pkg/lru"
 	"user-management/pkg/postgres_client"
 	"user-management/pkg/processor"
+	"user-management/pkg/redis_cache"
+	"user-management/pkg/redis_client"
 	"user-management/pkg/token_utils"
+	"user-management/pkg/tracing"
+	"user-management/pkg/validator"
 
 	"github.com/lmittmann/tint"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 var (
-	cfgs           *configs.Config
-	logger         log.Logger
-	httpServer     *http_server.HttpServer
-	postgresClient *postgres_client.PostgresClient
+	cfgs            *configs.Config
+	logger          log.Logger
+	httpServer      *http_server.HttpServer
+	adminServer     *http_server.HttpServer
+	postgresClient  *postgres_client.PostgresClient
+	redisClient     *redis_client.RedisClient
+	invalidationBus *invalidation.Bus
+	traceProvider   *tracing.Provider
+	healthChecker   *health.Checker
 
-	userByUserNameCache cache.Cache[string, *entities.User]
-	userCache           cache.Cache[int64, *entities.UserWithAccounts]
-	accountCache        cache.Cache[int64, *entities.Account]
+	metricsRegistry *prometheus.Registry
+	lruMetrics      *lru.Metrics
+
+	userByUserNameCache cache.LoadingCache[string, *entities.User]
+	userCache           cache.LoadingCache[int64, *entities.UserWithAccounts]
+	accountCache        cache.LoadingCache[int64, *entities.Account]
+	revocationCache     cache.Cache[string, time.Time]
+	// failed logins are counted by loginFailureCounter and lockouts are stored in loginLockoutCache,
+	// failures of unknown usernames are kept apart, so flooding unknown usernames can not evict failures of users.
+	loginFailureCounter            lockout.Counter
+	loginLockoutCache              cache.Cache[string, time.Time]
+	unknownUserLoginFailureCounter lockout.Counter
+	unknownUserLoginLockoutCache   cache.Cache[string, time.Time]
+	// cacheRegistry is the set of caches managed by the admin listener.
+	cacheRegistry *cache.Registry
+
+	// changes are published by repositories after writes are committed.
+	changeBus *events.Bus[entities.Change]
 
 	idGenerator    id_utils.IDGenerator
 	tokenGenerator token_utils.Authenticator[*xcontext.UserInfo]
+	passwordHasher crypto_utils.PasswordHasher
+	// challengeGenerator issues challenge tokens of login and mfaSealer seals totp secrets,
+	// both by keys derived for them only.
+	challengeGenerator token_utils.Authenticator[*entities.MFAChallenge]
+	mfaSealer          *crypto_utils.Sealer
 
-	userService    services.UserService
-	authService    services.AuthService
-	accountService services.AccountService
+	userService            services.UserService
+	authService            services.AuthService
+	accountService         services.AccountService
+	transferService        services.TransferService
+	tokenRevocationService services.TokenRevocationService
+	adminService           services.AdminService
+	mfaService             services.MFAService
 
 	processors []processor.Processor
 	factories  []processor.Factory
@@ -60,7 +103,18 @@ func loadConfigs() {
 }
 
 func loadLogger() {
-	logger = slog.New(tint.NewHandler(os.Stdout, nil))
+	l := slog.New(tint.NewHandler(os.Stdout, nil))
+	// logs without a request context fallback to the default logger.
+	slog.SetDefault(l)
+	logger = l
+}
+
+func loadTracing() {
+	var err error
+	traceProvider, err = tracing.NewProvider("user-management", cfgs.Tracing.Exporter, cfgs.Tracing.OTLPEndpoint)
+	if err != nil {
+		l.Fatalf("unable to load tracing: %v", err)
+	}
 }
 
 func loadGenerators() {
@@ -70,6 +124,40 @@ func loadGenerators() {
 	if err != nil {
 		l.Fatalf("unable to create new token generator: %v", err)
 	}
+
+	hashing := cfgs.PasswordHashing
+	passwordHasher, err = crypto_utils.NewPasswordHasher(hashing.Algorithm, crypto_utils.Argon2idParams{
+		Memory:      hashing.Argon2Memory,
+		Iterations:  hashing.Argon2Iterations,
+		Parallelism: hashing.Argon2Parallelism,
+		SaltLength:  crypto_utils.DefaultArgon2idParams.SaltLength,
+		KeyLength:   crypto_utils.DefaultArgon2idParams.KeyLength,
+	}, hashing.BcryptCost)
+	if err != nil {
+		l.Fatalf("unable to create password hasher: %v", err)
+	}
+
+	challengeKey, err := crypto_utils.DeriveKey(cfgs.SymetricKey, "mfa challenge", chacha20poly1305.KeySize)
+	if err != nil {
+		l.Fatalf("unable to derive key of mfa challenge: %v", err)
+	}
+	challengeGenerator, err = token_utils.NewPasetoAuthenticator[*entities.MFAChallenge](string(challengeKey))
+	if err != nil {
+		l.Fatalf("unable to create mfa challenge generator: %v", err)
+	}
+
+	secretKey := cfgs.MFA.SecretKey
+	if secretKey == "" {
+		secretKey = cfgs.SymetricKey
+	}
+	sealKey, err := crypto_utils.DeriveKey(secretKey, "mfa secret", chacha20poly1305.KeySize)
+	if err != nil {
+		l.Fatalf("unable to derive key of mfa secret: %v", err)
+	}
+	mfaSealer, err = crypto_utils.NewSealer(sealKey)
+	if err != nil {
+		l.Fatalf("unable to create mfa sealer: %v", err)
+	}
 }
 
 func loadHttpServer() {
@@ -78,29 +166,147 @@ func loadHttpServer() {
 		logger,
 
 		// middlewares will be handle by passing order.
-		http_server.WithCors(), // using default allow access origin
-		http_server.WithAuthenticate(tokenGenerator, []string{
+		http_server.WithRequestID(logger),               // first, so logs of all middlewares include the request id
+		http_server.WithClientIP(cfgs.TrustedProxyHops), // before access log, so logs include the client ip
+		http_server.WithMetrics(metricsRegistry),        // before others, so responses of all middlewares are recorded
+		http_server.WithTracing(),                       // before authenticate, so rejected requests are traced too
+		http_server.WithAccessLog(),                     // logs the final status of every request
+		http_server.WithCors(),                          // using default allow access origin
+		http_server.WithAuthenticate(tokenGenerator, tokenRevocationService, []string{
 			"POST /auth/login",
-			"GET /users/{id}",
-			"GET /users/{id}/accounts",
+			"POST /auth/login/mfa",
+			"POST /auth/refresh",
+			"GET /healthz",
+			"GET /readyz",
 		}),
 		http_server.WithRBAC(map[string][]entities.User_Role{
-			"POST /users":   {entities.SuperAdminRole, entities.AdminRole},
-			"PUT /users":    {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
-			"DELETE /users": {entities.SuperAdminRole, entities.AdminRole},
+			"POST /users":        {entities.SuperAdminRole, entities.AdminRole},
+			"PUT /users/{id}":    {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
+			"DELETE /users/{id}": {entities.SuperAdminRole, entities.AdminRole},
 
 			"POST /users/{id}/accounts": {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
 			"PUT /accounts/{id}":        {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
 			"DELETE /accounts/{id}":     {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
+
+			"POST /transfers": {entities.SuperAdminRole, entities.AdminRole, entities.UserRole},
+
+			"DELETE /auth/lockouts/{user_name}": {entities.SuperAdminRole, entities.AdminRole},
+			"DELETE /users/{id}/mfa":            {entities.SuperAdminRole, entities.AdminRole},
 		}),
-		http_server.WithRecovery(logger),
+		http_server.WithRecovery(),
 	)
 }
 
+// loadAdminServer creates the admin listener serving metrics, profiles and cache management if its port is configured.
+func loadAdminServer() {
+	if cfgs.AdminHTTP.Port == "" {
+		return
+	}
+
+	adminServer = http_server.NewHttpServer(
+		cfgs.AdminHTTP,
+		logger,
+
+		http_server.WithRequestID(logger),
+		http_server.WithAccessLog(),
+		// only scrapers and probes are allowed without super admin token or admin key.
+		http_server.WithAdmin(tokenGenerator, tokenRevocationService, cfgs.AdminKey, []string{
+			"GET /metrics",
+			"GET /healthz",
+			"GET /readyz",
+		}),
+		http_server.WithRecovery(),
+	)
+	http_server.RegisterHandler(adminServer, http.MethodGet, "/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+}
+
+func loadMetrics() {
+	metricsRegistry = prometheus.NewRegistry()
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		postgresClient,
+	)
+	lruMetrics = lru.NewMetrics(metricsRegistry)
+}
+
 func loadCaches() {
-	userCache = lru.NewLRU[int64, *entities.UserWithAccounts](128, 24*time.Hour)
-	accountCache = lru.NewLRU[int64, *entities.Account](128, 24*time.Hour)
-	userByUserNameCache = lru.NewLRU[string, *entities.User](128, 24*time.Hour)
+	var (
+		userStore           cache.Cache[int64, *cache.Entry[*entities.UserWithAccounts]]
+		accountStore        cache.Cache[int64, *cache.Entry[*entities.Account]]
+		userByUserNameStore cache.Cache[string, *cache.Entry[*entities.User]]
+	)
+
+	// failures expiring immediately would never lock a key.
+	if cfgs.LoginThrottling.FailureWindow <= 0 {
+		l.Fatalf("login failure window must be greater than zero")
+	}
+	// lockouts must outlive the longest lockout, otherwise a locked key is forgotten before it is unlocked,
+	// and failures outlive it too, so a failure after a lockout doubles it.
+	loginAttemptTTL := max(cfgs.LoginThrottling.FailureWindow, cfgs.LoginThrottling.MaxLockout)
+
+	switch cfgs.CacheDriver {
+	case "lru":
+		userStore = lru.NewLRU[int64, *cache.Entry[*entities.UserWithAccounts]](128, 24*time.Hour, lru.WithMetrics(lruMetrics, "user"))
+		accountStore = lru.NewLRU[int64, *cache.Entry[*entities.Account]](128, 24*time.Hour, lru.WithMetrics(lruMetrics, "account"))
+		userByUserNameStore = lru.NewLRU[string, *cache.Entry[*entities.User]](128, 24*time.Hour, lru.WithMetrics(lruMetrics, "user_by_user_name"))
+		// revocations are also cached as "not revoked", the short ttl bounds how long
+		// other instances may accept a revoked token.
+		revocationCache = lru.NewLRU[string, time.Time](4096, time.Minute, lru.WithMetrics(lruMetrics, "revocation"))
+		// failed logins are counted by each replica, so a client may try once per replica before it is locked.
+		loginFailureCounter = lockout.NewMemoryCounter(lru.NewLRU[string, int64](16384, loginAttemptTTL, lru.WithMetrics(lruMetrics, "login_attempt")))
+		loginLockoutCache = lru.NewLRU[string, time.Time](16384, loginAttemptTTL, lru.WithMetrics(lruMetrics, "login_lockout"))
+		unknownUserLoginFailureCounter = lockout.NewMemoryCounter(lru.NewLRU[string, int64](16384, loginAttemptTTL, lru.WithMetrics(lruMetrics, "unknown_user_login_attempt")))
+		unknownUserLoginLockoutCache = lru.NewLRU[string, time.Time](16384, loginAttemptTTL, lru.WithMetrics(lruMetrics, "unknown_user_login_lockout"))
+	case "redis":
+		codec, err := redis_cache.CodecByName(cfgs.CacheCodec)
+		if err != nil {
+			l.Fatalf("unable to load caches: %v", err)
+		}
+
+		redisClient = redis_client.NewRedisClient(cfgs.Redis.Address(), cfgs.Redis.Password, cfgs.Redis.DB)
+		userStore = redis_cache.NewRedisCache[int64, *cache.Entry[*entities.UserWithAccounts]](redisClient, "user", 24*time.Hour, codec)
+		accountStore = redis_cache.NewRedisCache[int64, *cache.Entry[*entities.Account]](redisClient, "account", 24*time.Hour, codec)
+		userByUserNameStore = redis_cache.NewRedisCache[string, *cache.Entry[*entities.User]](redisClient, "user_by_user_name", 24*time.Hour, codec)
+		// revocations are shared by all replicas, the ttl only bounds the size of cache.
+		revocationCache = redis_cache.NewRedisCache[string, time.Time](redisClient, "revocation", time.Minute, codec)
+		loginFailureCounter = redis_cache.NewRedisCounter(redisClient, "login_attempt", loginAttemptTTL)
+		loginLockoutCache = redis_cache.NewRedisCache[string, time.Time](redisClient, "login_lockout", loginAttemptTTL, codec)
+		unknownUserLoginFailureCounter = redis_cache.NewRedisCounter(redisClient, "unknown_user_login_attempt", loginAttemptTTL)
+		unknownUserLoginLockoutCache = redis_cache.NewRedisCache[string, time.Time](redisClient, "unknown_user_login_lockout", loginAttemptTTL, codec)
+	default:
+		l.Fatalf("cache driver %q is not supported", cfgs.CacheDriver)
+	}
+
+	// entries are refreshed in background after a minute and missing keys are remembered briefly,
+	// so a burst of requests for the same key only reaches postgres once.
+	loaderOptions := []cache.LoaderOption{
+		cache.WithStaleWhileRevalidate(time.Minute),
+		cache.WithNotFoundTTL(10 * time.Second),
+	}
+	userCache = cache.NewLoader(userStore, loaderOptions...)
+	accountCache = cache.NewLoader(accountStore, loaderOptions...)
+	// users by user name are used by login, a stale password hash or role must never be served,
+	// so entries are only removed by changes and never served stale.
+	userByUserNameCache = cache.NewLoader(userByUserNameStore, cache.WithNotFoundTTL(10*time.Second))
+
+	// caches in memory are invalidated on all replicas when a key is removed,
+	// redis caches are already shared so they do not need it.
+	if cfgs.CacheDriver == "lru" {
+		invalidationBus = invalidation.NewBus(postgresClient, logger)
+		userCache = invalidation.Register(invalidationBus, "user", userCache)
+		accountCache = invalidation.Register(invalidationBus, "account", accountCache)
+		userByUserNameCache = invalidation.Register(invalidationBus, "user_by_user_name", userByUserNameCache)
+	}
+
+	// caches are registered after wrapping, so purging or removing a key by admin also reaches other replicas.
+	cacheRegistry = cache.NewRegistry()
+	cache.Register(cacheRegistry, "user", userCache)
+	cache.Register(cacheRegistry, "account", accountCache)
+	cache.Register(cacheRegistry, "user_by_user_name", userByUserNameCache)
+	cache.Register(cacheRegistry, "revocation", revocationCache)
+	cache.Register(cacheRegistry, "login_lockout", loginLockoutCache)
+	cache.Register(cacheRegistry, "unknown_user_login_lockout", unknownUserLoginLockoutCache)
 }
 
 func loadPostgresClient() {
@@ -108,41 +314,143 @@ func loadPostgresClient() {
 }
 
 func loadServices() {
-	userService = services.NewUserService(
+	changeBus = events.NewBus[entities.Change]()
+	changeBus.Subscribe(services.NewCacheCoherenceHandler(userCache, userByUserNameCache, accountCache))
+
+	// services are decorated by tracing, spans are no-op while the trace exporter is none.
+	tokenRevocationService = services.WithTokenRevocationTracing(
+		services.NewTokenRevocationService(postgresClient, revocationCache),
+	)
+
+	userService = services.WithUserTracing(services.NewUserService(
 		postgresClient,
 		idGenerator,
+		passwordHasher,
 		userCache,
 		userByUserNameCache,
-	)
+		changeBus,
+		tokenRevocationService,
+	))
 
-	accountService = services.NewAccountService(postgresClient, accountCache)
+	accountService = services.WithAccountTracing(services.NewAccountService(postgresClient, accountCache, changeBus))
 
-	authService = services.NewAuthService(
-		postgresClient,
-		idGenerator,
-		tokenGenerator,
-		userByUserNameCache,
-	)
+	transferService = services.WithTransferTracing(services.NewTransferService(postgresClient, idGenerator, changeBus))
+
+	adminService = services.NewAdminService(cacheRegistry, cfgs.Redacted())
+
+	mfaService = services.WithMFATracing(services.NewMFAService(postgresClient, idGenerator, mfaSealer, cfgs.MFA.Issuer))
+
+	// unknown usernames are locked out like existing ones, so a lockout does not tell whether a username exists.
+	userLockoutPolicy := lockout.Policy{
+		MaxFailures: cfgs.LoginThrottling.MaxUserFailures,
+		BaseLockout: cfgs.LoginThrottling.BaseLockout,
+		MaxLockout:  cfgs.LoginThrottling.MaxLockout,
+	}
+	userLockout, err := lockout.NewTracker(loginFailureCounter, loginLockoutCache, "user:", userLockoutPolicy)
+	if err != nil {
+		l.Fatalf("unable to create login lockout: %v", err)
+	}
+	unknownUserLockout, err := lockout.NewTracker(unknownUserLoginFailureCounter, unknownUserLoginLockoutCache, "user:", userLockoutPolicy)
+	if err != nil {
+		l.Fatalf("unable to create login lockout: %v", err)
+	}
+	ipLockout, err := lockout.NewTracker(loginFailureCounter, loginLockoutCache, "ip:", lockout.Policy{
+		MaxFailures: cfgs.LoginThrottling.MaxIPFailures,
+		BaseLockout: cfgs.LoginThrottling.BaseLockout,
+		MaxLockout:  cfgs.LoginThrottling.MaxLockout,
+	})
+	if err != nil {
+		l.Fatalf("unable to create login lockout: %v", err)
+	}
+
+	authService = services.WithAuthTracing(services.NewAuthService(services.AuthServiceDeps{
+		PGClient:               postgresClient,
+		IDGenerator:            idGenerator,
+		PasswordHasher:         passwordHasher,
+		TokenGenerator:         tokenGenerator,
+		AccessTokenTTL:         cfgs.AccessTokenTTL,
+		RefreshTokenTTL:        cfgs.RefreshTokenTTL,
+		ChallengeGenerator:     challengeGenerator,
+		ChallengeTTL:           cfgs.MFA.ChallengeTTL,
+		MFAService:             mfaService,
+		UserByUserNameCache:    userByUserNameCache,
+		TokenRevocationService: tokenRevocationService,
+		UserLockout:            userLockout,
+		UnknownUserLockout:     unknownUserLockout,
+		IPLockout:              ipLockout,
+		Changes:                changeBus,
+		Registerer:             metricsRegistry,
+	}))
+}
+
+func loadHealthChecker() {
+	healthChecker = health.NewChecker(cfgs.HealthCheckTimeout)
+}
+
+// registerEnums registers enums used by "enum" rules of requests, they must be registered before handlers.
+func registerEnums() {
+	validator.RegisterEnum("user_role", entities.UserRoleList...)
+	validator.RegisterEnum("account_status", entities.AccountStatusList...)
 }
 
 func registerHandlers() {
+	// probes are served by both listeners, the admin listener keeps answering while the http server is draining.
+	for _, s := range []*http_server.HttpServer{httpServer, adminServer} {
+		if s == nil {
+			continue
+		}
+		http_server.RegisterHandler(s, http.MethodGet, "/healthz", healthChecker.LivenessHandler())
+	}
+	// errors of dependencies are only served to operators by the admin listener.
+	http_server.RegisterHandler(httpServer, http.MethodGet, "/readyz", healthChecker.ReadinessHandler())
+	if adminServer != nil {
+		http_server.RegisterHandler(adminServer, http.MethodGet, "/readyz", healthChecker.DetailedReadinessHandler())
+	}
+
 	deliveries.RegisterUserDelivery(httpServer, userService)
 	deliveries.RegisterAuthDelivery(httpServer, authService)
+	deliveries.RegisterMFADelivery(httpServer, mfaService)
 	deliveries.RegisterAccountDelivery(httpServer, accountService)
+	deliveries.RegisterTransferDelivery(httpServer, transferService)
+	if adminServer != nil {
+		deliveries.RegisterAdminDelivery(adminServer, adminService)
+	}
 }
 
 func registerFactories() {
-	factories = append(factories, postgresClient)
+	// the tracer provider is connected first and closed last, so spans of closing other factories are exported.
+	registerFactory("tracing", traceProvider)
+	registerFactory("postgres", postgresClient)
+	if redisClient != nil {
+		registerFactory("redis", redisClient)
+	}
+}
+
+// registerFactory registers f to be connected and closed, f is checked by readiness if it has a health check.
+func registerFactory(name string, f processor.Factory) {
+	factories = append(factories, f)
+	healthChecker.AddFactory(name, f)
 }
 
 func registerProcessors() {
+	// the bus is stopped after the http server, so invalidations of in-flight requests are still received.
+	if invalidationBus != nil {
+		processors = append(processors, invalidationBus)
+	}
+	// the admin server is stopped after the http server, so metrics can be scraped while draining.
+	if adminServer != nil {
+		processors = append(processors, adminServer)
+	}
 	processors = append(processors, httpServer)
 }
 
 func migrateAdmin(ctx context.Context) {
 	id := idGenerator.Int64()
-	pwd, _ := crypto_utils.HashPassword(cfgs.SuperAdminPassword)
-	userRepo := repositories.NewUserRepository()
+	pwd, err := passwordHasher.Hash(cfgs.SuperAdminPassword)
+	if err != nil {
+		l.Fatalf("unable to hash password of super admin: %v", err)
+	}
+	userRepo := repositories.NewUserRepository(changeBus)
 	if err := userRepo.Upsert(ctx, postgresClient, &entities.User{
 		ID:        id,
 		Name:      database.NullString("admin"),
@@ -159,44 +467,72 @@ func loadDefault() {
 	// loader
 	loadConfigs()
 	loadLogger()
+	loadTracing()
 	loadGenerators()
 	loadPostgresClient()
+	loadMetrics()
 	loadCaches()
 	loadServices()
 	loadHttpServer()
+	loadAdminServer()
+	loadHealthChecker()
 
 	// register
+	registerEnums()
 	registerHandlers()
 	registerFactories()
 	registerProcessors()
 }
 
-func start(ctx context.Context, errChan chan error) {
+// start connects all factories and then starts all processors in background,
+// an error of a running processor will be sent to errChan.
+func start(ctx context.Context, errChan chan error) error {
 	for _, f := range factories {
 		if err := f.Connect(ctx); err != nil {
-			errChan <- err
+			return fmt.Errorf("unable to connect factory %T: %w", f, err)
 		}
 	}
 
 	for _, p := range processors {
 		go func(pr processor.Processor) {
 			if err := pr.Start(ctx); err != nil {
-				errChan <- err
+				errChan <- fmt.Errorf("processor %T stopped: %w", pr, err)
 			}
 		}(p)
 	}
+
+	return nil
 }
 
+// stop marks the service as not ready and stops processors in reverse order of starting, so in-flight requests are drained
+// before their dependencies, and then closes factories in reverse order of connecting.
 func stop(ctx context.Context) {
-	for _, f := range factories {
-		if err := f.Close(ctx); err != nil {
-			logger.Error("unable to stop factory", "err", err)
-		}
+	// readiness fails first and requests are still served for the drain delay,
+	// so orchestrators see the service as not ready and stop routing new requests before the server stops.
+	healthChecker.Drain()
+	logger.Info("draining before stopping processors", "delay", cfgs.ShutdownDrainDelay)
+	select {
+	case <-time.After(cfgs.ShutdownDrainDelay):
+	case <-ctx.Done():
 	}
 
-	for _, p := range processors {
+	for i := len(processors) - 1; i >= 0; i-- {
+		p := processors[i]
+		logger.Info("stopping processor", "processor", fmt.Sprintf("%T", p))
 		if err := p.Stop(ctx); err != nil {
-			logger.Error("unable to stop processor", "err", err)
+			logger.Error("unable to stop processor", "processor", fmt.Sprintf("%T", p), "err", err)
+			continue
+		}
+		logger.Info("processor stopped", "processor", fmt.Sprintf("%T", p))
+	}
+
+	for i := len(factories) - 1; i >= 0; i-- {
+		f := factories[i]
+		logger.Info("closing factory", "factory", fmt.Sprintf("%T", f))
+		if err := f.Close(ctx); err != nil {
+			logger.Error("unable to close factory", "factory", fmt.Sprintf("%T", f), "err", err)
+			continue
 		}
+		logger.Info("factory closed", "factory", fmt.Sprintf("%T", f))
 	}
 }
@@ -4,30 +4,47 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/spf13/cobra"
 )
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Short: "Start the http server",
+	Long: `Start the http server with all processors and factories.
+The server is stopped gracefully on SIGINT or SIGTERM, readiness fails for SHUTDOWN_DRAIN_DELAY,
+in-flight requests are drained until SHUTDOWN_TIMEOUT and then processors and factories are stopped in reverse order.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := cmd.Context()
+		// ctx is canceled when receiving SIGINT or SIGTERM.
+		ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+
 		loadDefault()
-		errChan := make(chan error)
-		start(ctx, errChan)
+		errChan := make(chan error, len(processors))
+		if err := start(ctx, errChan); err != nil {
+			logger.Error("unable to start", "err", err)
+			stop(context.Background())
+			os.Exit(1)
+		}
 		migrateAdmin(ctx)
-		err := <-errChan
-		if err != nil {
-			logger.Error(err.Error())
-			stop(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Info("received shutdown signal, shutting down", "timeout", cfgs.ShutdownTimeout)
+		case err := <-errChan:
+			logger.Error("shutting down because of processor error", "err", err)
 		}
+
+		// ctx is already canceled, so draining uses a fresh context bounded by shutdown timeout.
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfgs.ShutdownTimeout)
+		defer shutdownCancel()
+		stop(shutdownCtx)
+		logger.Info("shutdown completed")
 	},
 }
 
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -9,8 +10,42 @@ import (
 type Config struct {
 	PostgresDB *Database
 	HTTP       *Endpoint
+	// AdminHTTP is the endpoint of admin listener serving metrics, profiles and cache management, it is disabled if port is empty.
+	AdminHTTP *Endpoint
+	// AdminKey is a static key accepted by the admin listener besides tokens of super admins, it is disabled if empty.
+	AdminKey string
+	Redis    *Redis
+	Tracing  *Tracing
+	// PasswordHashing is how passwords are hashed, login rehashes passwords of other algorithms or parameters.
+	PasswordHashing *PasswordHashing
+	// LoginThrottling is when usernames and client ips are locked out by failed logins.
+	LoginThrottling *LoginThrottling
+	// MFA is how TOTP of two-factor authentication is issued and verified.
+	MFA *MFA
+	// TrustedProxyHops is the number of proxies in front of the server appending to "X-Forwarded-For",
+	// zero uses the remote address of connection as client ip.
+	TrustedProxyHops int
+
+	// CacheDriver is where caches are stored, "lru" keeps them in memory of each replica and "redis" shares them.
+	CacheDriver string
+	// CacheCodec is the encoding of values stored in redis, "json" or "msgpack".
+	CacheCodec string
+
+	// ShutdownTimeout is the maximum duration to drain in-flight requests when the server is stopping.
+	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long readiness fails before processors are stopped,
+	// so orchestrators see the service as not ready and stop routing new requests to it.
+	ShutdownDrainDelay time.Duration
+	// HealthCheckTimeout is the maximum duration of every check of readiness probe.
+	HealthCheckTimeout time.Duration
+
+	SymetricKey string
+	// AccessTokenTTL is the lifetime of access tokens, it should be short because revocations of access tokens
+	// are cached for a while before they are rejected by other instances.
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of refresh tokens which are used to issue new access tokens.
+	RefreshTokenTTL time.Duration
 
-	SymetricKey        string
 	SuperAdminUsername string
 	SuperAdminPassword string
 }
@@ -25,7 +60,45 @@ type config struct {
 	HttpHost string `mapstructure:"HTTP_HOST"`
 	HttpPort string `mapstructure:"HTTP_PORT"`
 
-	SymetricKey string `mapstructure:"SYMETRIC_KEY"`
+	AdminHttpHost string `mapstructure:"ADMIN_HTTP_HOST"`
+	AdminHttpPort string `mapstructure:"ADMIN_HTTP_PORT"`
+	AdminKey      string `mapstructure:"ADMIN_KEY"`
+
+	RedisHost     string `mapstructure:"REDIS_HOST"`
+	RedisPort     string `mapstructure:"REDIS_PORT"`
+	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
+	RedisDB       int    `mapstructure:"REDIS_DB"`
+
+	CacheDriver string `mapstructure:"CACHE_DRIVER"`
+	CacheCodec  string `mapstructure:"CACHE_CODEC"`
+
+	TraceExporter     string `mapstructure:"TRACE_EXPORTER"`
+	TraceOTLPEndpoint string `mapstructure:"TRACE_OTLP_ENDPOINT"`
+
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	Argon2Memory          uint32 `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations      uint32 `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism     uint8  `mapstructure:"ARGON2_PARALLELISM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+
+	LoginMaxUserFailures int           `mapstructure:"LOGIN_MAX_USER_FAILURES"`
+	LoginMaxIPFailures   int           `mapstructure:"LOGIN_MAX_IP_FAILURES"`
+	LoginBaseLockout     time.Duration `mapstructure:"LOGIN_BASE_LOCKOUT"`
+	LoginMaxLockout      time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT"`
+	LoginFailureWindow   time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+	TrustedProxyHops     int           `mapstructure:"TRUSTED_PROXY_HOPS"`
+
+	MFAIssuer       string        `mapstructure:"MFA_ISSUER"`
+	MFAChallengeTTL time.Duration `mapstructure:"MFA_CHALLENGE_TTL"`
+	MFASecretKey    string        `mapstructure:"MFA_SECRET_KEY"`
+
+	ShutdownTimeout    time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+
+	SymetricKey     string        `mapstructure:"SYMETRIC_KEY"`
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
 
 	SuperAdminUsername string `mapstructure:"SUPER_ADMIN_USERNAME"`
 	SuperAdminPassword string `mapstructure:"SUPER_ADMIN_PASSWORD"`
@@ -38,6 +111,28 @@ func LoadConfig(path string, env string) (*Config, error) {
 	viper.SetConfigType("env")
 
 	viper.AutomaticEnv()
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", 5*time.Second)
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	viper.SetDefault("ACCESS_TOKEN_TTL", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	viper.SetDefault("CACHE_DRIVER", "lru")
+	viper.SetDefault("CACHE_CODEC", "json")
+	viper.SetDefault("TRACE_EXPORTER", "none")
+	// minimum parameters of argon2id recommended by OWASP.
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("ARGON2_MEMORY", 19*1024)
+	viper.SetDefault("ARGON2_ITERATIONS", 2)
+	viper.SetDefault("ARGON2_PARALLELISM", 1)
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("LOGIN_MAX_USER_FAILURES", 5)
+	viper.SetDefault("LOGIN_MAX_IP_FAILURES", 50)
+	viper.SetDefault("LOGIN_BASE_LOCKOUT", time.Minute)
+	viper.SetDefault("LOGIN_MAX_LOCKOUT", time.Hour)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", 24*time.Hour)
+	viper.SetDefault("TRUSTED_PROXY_HOPS", 0)
+	viper.SetDefault("MFA_ISSUER", "user-management")
+	viper.SetDefault("MFA_CHALLENGE_TTL", 5*time.Minute)
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("unable to read config file: %w", err)
@@ -59,7 +154,51 @@ func LoadConfig(path string, env string) (*Config, error) {
 			Host: cfg.HttpHost,
 			Port: cfg.HttpPort,
 		},
+		AdminHTTP: &Endpoint{
+			Host: cfg.AdminHttpHost,
+			Port: cfg.AdminHttpPort,
+		},
+		AdminKey: cfg.AdminKey,
+		Redis: &Redis{
+			Endpoint: Endpoint{
+				Host: cfg.RedisHost,
+				Port: cfg.RedisPort,
+			},
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		},
+		Tracing: &Tracing{
+			Exporter:     cfg.TraceExporter,
+			OTLPEndpoint: cfg.TraceOTLPEndpoint,
+		},
+		PasswordHashing: &PasswordHashing{
+			Algorithm:         cfg.PasswordHashAlgorithm,
+			Argon2Memory:      cfg.Argon2Memory,
+			Argon2Iterations:  cfg.Argon2Iterations,
+			Argon2Parallelism: cfg.Argon2Parallelism,
+			BcryptCost:        cfg.BcryptCost,
+		},
+		LoginThrottling: &LoginThrottling{
+			MaxUserFailures: cfg.LoginMaxUserFailures,
+			MaxIPFailures:   cfg.LoginMaxIPFailures,
+			BaseLockout:     cfg.LoginBaseLockout,
+			MaxLockout:      cfg.LoginMaxLockout,
+			FailureWindow:   cfg.LoginFailureWindow,
+		},
+		MFA: &MFA{
+			Issuer:       cfg.MFAIssuer,
+			ChallengeTTL: cfg.MFAChallengeTTL,
+			SecretKey:    cfg.MFASecretKey,
+		},
+		TrustedProxyHops:   cfg.TrustedProxyHops,
+		CacheDriver:        cfg.CacheDriver,
+		CacheCodec:         cfg.CacheCodec,
+		ShutdownTimeout:    cfg.ShutdownTimeout,
+		ShutdownDrainDelay: cfg.ShutdownDrainDelay,
+		HealthCheckTimeout: cfg.HealthCheckTimeout,
 		SymetricKey:        cfg.SymetricKey,
+		AccessTokenTTL:     cfg.AccessTokenTTL,
+		RefreshTokenTTL:    cfg.RefreshTokenTTL,
 		SuperAdminUsername: cfg.SuperAdminUsername,
 		SuperAdminPassword: cfg.SuperAdminPassword,
 	}, nil
@@ -0,0 +1,16 @@
+package configs
+
+import "time"
+
+type LoginThrottling struct {
+	// MaxUserFailures is the number of failed logins locking a username out.
+	MaxUserFailures int
+	// MaxIPFailures is the number of failed logins locking a client ip out, it is larger than MaxUserFailures
+	// because users behind the same NAT share an ip.
+	MaxIPFailures int
+	// BaseLockout is the first lockout, it is doubled by every further failure up to MaxLockout.
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	// FailureWindow is how long failures are remembered since the last failure.
+	FailureWindow time.Duration
+}
@@ -0,0 +1,13 @@
+package configs
+
+import "time"
+
+type MFA struct {
+	// Issuer is shown by authenticator apps beside the user name.
+	Issuer string
+	// ChallengeTTL is the lifetime of challenge tokens issued by the password step of login.
+	ChallengeTTL time.Duration
+	// SecretKey seals TOTP secrets, it is derived from the symmetric key if empty.
+	// It should be set, otherwise rotating the symmetric key makes secrets unreadable.
+	SecretKey string
+}
@@ -0,0 +1,11 @@
+package configs
+
+type PasswordHashing struct {
+	// Algorithm hashes new passwords, "argon2id" or "bcrypt". Hashes of both algorithms are verified.
+	Algorithm string
+	// Argon2Memory is the memory of argon2id in KiB.
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+}
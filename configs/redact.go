@@ -0,0 +1,37 @@
+package configs
+
+// redacted replaces secrets which are set, so it is still visible whether a secret is configured.
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of c with all secrets replaced, it is safe to be logged or served.
+func (c *Config) Redacted() *Config {
+	out := *c
+	if c.PostgresDB != nil {
+		db := *c.PostgresDB
+		db.Password = redact(db.Password)
+		out.PostgresDB = &db
+	}
+	if c.Redis != nil {
+		r := *c.Redis
+		r.Password = redact(r.Password)
+		out.Redis = &r
+	}
+	if c.MFA != nil {
+		m := *c.MFA
+		m.SecretKey = redact(m.SecretKey)
+		out.MFA = &m
+	}
+	out.SymetricKey = redact(c.SymetricKey)
+	out.SuperAdminPassword = redact(c.SuperAdminPassword)
+	out.AdminKey = redact(c.AdminKey)
+
+	return &out
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redacted
+}
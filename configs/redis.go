@@ -0,0 +1,7 @@
+package configs
+
+type Redis struct {
+	Endpoint
+	Password string
+	DB       int
+}
@@ -0,0 +1,8 @@
+package configs
+
+type Tracing struct {
+	// Exporter is where spans are exported, "none", "stdout" or "otlp".
+	Exporter string
+	// OTLPEndpoint is the url of otlp http receiver (ex: http://localhost:4318), empty uses OTEL_EXPORTER_OTLP_* envs.
+	OTLPEndpoint string
+}
@@ -2,16 +2,26 @@ package deliveries
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
+	"time"
+
+	"user-management/internal/entities"
 	"user-management/internal/models"
 	"user-management/internal/services"
+	"user-management/pkg/database"
 	"user-management/pkg/http_server"
+	"user-management/pkg/xerrors"
 )
 
 // using skeleton with cmd (d *accountDelivery AccountDelivery)
 
 type AccountDelivery interface {
 	GetAccountByID(context.Context, *models.GetAccountByIDRequest) (*models.GetAccountByIDResponse, error)
+	ListTransactionByAccountID(context.Context, *models.ListTransactionByAccountIDRequest) (*models.ListTransactionByAccountIDResponse, error)
+	UpdateAccount(context.Context, *models.UpdateAccountRequest) (*models.UpdateAccountResponse, error)
+	DeleteAccount(context.Context, *models.DeleteAccountRequest) (*models.DeleteAccountResponse, error)
 }
 
 type accountDelivery struct {
@@ -30,7 +40,11 @@ func RegisterAccountDelivery(
 	}
 
 	http_server.Register(server, http.MethodGet, "/accounts/{id}", delivery.GetAccountByID)
+	http_server.Register(server, http.MethodPut, "/accounts/{id}", delivery.UpdateAccount)
+	http_server.Register(server, http.MethodDelete, "/accounts/{id}", delivery.DeleteAccount)
+	http_server.Register(server, http.MethodGet, "/accounts/{id}/transactions", delivery.ListTransactionByAccountID)
 }
+
 func (d *accountDelivery) GetAccountByID(ctx context.Context, req *models.GetAccountByIDRequest) (*models.GetAccountByIDResponse, error) {
 	resp, err := d.accountService.GetAccountByID(ctx, req.ID)
 	if err != nil {
@@ -39,9 +53,91 @@ func (d *accountDelivery) GetAccountByID(ctx context.Context, req *models.GetAcc
 
 	return &models.GetAccountByIDResponse{
 		Account: &models.Account{
-			ID:      resp.ID,
-			Name:    resp.Name.String,
-			Balance: resp.Balance.Int64,
+			ID:       resp.ID,
+			Name:     resp.Name.String,
+			Balance:  resp.Balance.Int64,
+			Currency: resp.Currency,
+			Status:   string(resp.Status),
 		},
 	}, nil
 }
+
+func (d *accountDelivery) UpdateAccount(ctx context.Context, req *models.UpdateAccountRequest) (*models.UpdateAccountResponse, error) {
+	if err := d.accountService.Update(ctx, &entities.Account{
+		ID: req.ID,
+		// empty name keeps the current name
+		Name:   sql.NullString{String: req.Name, Valid: req.Name != ""},
+		Status: entities.Account_Status(req.Status),
+	}); err != nil {
+		return nil, fmt.Errorf("unable to update account by id: %w", err)
+	}
+
+	return &models.UpdateAccountResponse{}, nil
+}
+
+func (d *accountDelivery) DeleteAccount(ctx context.Context, req *models.DeleteAccountRequest) (*models.DeleteAccountResponse, error) {
+	if err := d.accountService.DeleteByID(ctx, req.ID); err != nil {
+		return nil, fmt.Errorf("unable to delete account by id: %w", err)
+	}
+
+	return &models.DeleteAccountResponse{}, nil
+}
+
+func (d *accountDelivery) ListTransactionByAccountID(ctx context.Context, req *models.ListTransactionByAccountIDRequest) (*models.ListTransactionByAccountIDResponse, error) {
+	filter := &entities.TransactionFilter{
+		Limit: int(req.Limit),
+	}
+
+	if req.From != "" {
+		from, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return nil, xerrors.InvalidArgument("from must be RFC3339 format: %w", err)
+		}
+		filter.PostedFrom = from
+	}
+
+	if req.To != "" {
+		to, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return nil, xerrors.InvalidArgument("to must be RFC3339 format: %w", err)
+		}
+		filter.PostedTo = to
+	}
+
+	if req.Cursor != "" {
+		postedAt, id, err := database.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, xerrors.InvalidArgument("%w", err)
+		}
+		filter.AfterPostedAt, filter.AfterID = postedAt, id
+	}
+
+	transactions, hasMore, err := d.accountService.ListTransactionByAccountID(ctx, req.ID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve transactions by account id: %w", err)
+	}
+
+	result := make([]*models.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, &models.Transaction{
+			ID:                    t.ID,
+			Amount:                t.Amount,
+			Direction:             string(t.Direction),
+			Description:           t.Description.String,
+			CounterpartyAccountID: t.CounterpartyAccountID.Int64,
+			PostedAt:              t.PostedAt,
+			RunningBalance:        t.BalanceAfter,
+		})
+	}
+
+	resp := &models.ListTransactionByAccountIDResponse{
+		Transactions: result,
+	}
+
+	if hasMore {
+		last := transactions[len(transactions)-1]
+		resp.NextCursor = database.EncodeCursor(last.PostedAt, last.ID)
+	}
+
+	return resp, nil
+}
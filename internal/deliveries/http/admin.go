@@ -0,0 +1,111 @@
+package deliveries
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"user-management/internal/models"
+	"user-management/internal/services"
+	"user-management/pkg/http_server"
+)
+
+type adminDelivery struct {
+	server       *http_server.HttpServer
+	adminService services.AdminService
+}
+
+// RegisterAdminDelivery is registration of admin APIs and profiles of [net/http/pprof] to the admin listener.
+func RegisterAdminDelivery(
+	server *http_server.HttpServer,
+	adminService services.AdminService,
+) {
+	delivery := &adminDelivery{
+		server:       server,
+		adminService: adminService,
+	}
+
+	http_server.Register(server, http.MethodGet, "/admin/runtime", delivery.GetRuntimeInfo)
+	http_server.Register(server, http.MethodGet, "/admin/config", delivery.GetConfig)
+	http_server.Register(server, http.MethodGet, "/admin/caches", delivery.ListCaches)
+	http_server.Register(server, http.MethodDelete, "/admin/caches/{name}", delivery.PurgeCache)
+	http_server.Register(server, http.MethodDelete, "/admin/caches/{name}/keys/{key}", delivery.RemoveCacheKey)
+
+	// named profiles (ex: heap, goroutine) are served by index.
+	http_server.RegisterHandler(server, http.MethodGet, "/debug/pprof/{name...}", http.HandlerFunc(pprof.Index))
+	http_server.RegisterHandler(server, http.MethodGet, "/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	http_server.RegisterHandler(server, http.MethodGet, "/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	http_server.RegisterHandler(server, http.MethodGet, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	http_server.RegisterHandler(server, http.MethodPost, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	http_server.RegisterHandler(server, http.MethodGet, "/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+}
+
+func (d *adminDelivery) GetRuntimeInfo(ctx context.Context, _ *models.GetRuntimeInfoRequest) (*models.GetRuntimeInfoResponse, error) {
+	info, err := d.adminService.GetRuntimeInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get runtime info: %w", err)
+	}
+
+	return &models.GetRuntimeInfoResponse{
+		StartedAt:     info.StartedAt,
+		UptimeSeconds: int64(time.Since(info.StartedAt).Seconds()),
+		Goroutines:    info.Goroutines,
+		GOMAXPROCS:    info.GOMAXPROCS,
+		NumCPU:        info.NumCPU,
+		HeapAlloc:     info.HeapAlloc,
+		Build: &models.BuildInfo{
+			GoVersion: info.Build.GoVersion,
+			Path:      info.Build.Path,
+			Version:   info.Build.Version,
+			Settings:  info.Build.Settings,
+		},
+	}, nil
+}
+
+func (d *adminDelivery) GetConfig(ctx context.Context, _ *models.GetConfigRequest) (*models.GetConfigResponse, error) {
+	config, err := d.adminService.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get config: %w", err)
+	}
+
+	return &models.GetConfigResponse{
+		Config: config,
+	}, nil
+}
+
+func (d *adminDelivery) ListCaches(ctx context.Context, _ *models.ListCachesRequest) (*models.ListCachesResponse, error) {
+	infos, err := d.adminService.ListCaches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list caches: %w", err)
+	}
+
+	caches := make([]*models.Cache, 0, len(infos))
+	for _, info := range infos {
+		caches = append(caches, &models.Cache{
+			Name: info.Name,
+			Size: info.Size,
+		})
+	}
+
+	return &models.ListCachesResponse{
+		Caches: caches,
+	}, nil
+}
+
+func (d *adminDelivery) PurgeCache(ctx context.Context, req *models.PurgeCacheRequest) (*models.PurgeCacheResponse, error) {
+	if err := d.adminService.PurgeCache(ctx, req.Name); err != nil {
+		return nil, fmt.Errorf("unable to purge cache: %w", err)
+	}
+
+	return &models.PurgeCacheResponse{}, nil
+}
+
+func (d *adminDelivery) RemoveCacheKey(ctx context.Context, req *models.RemoveCacheKeyRequest) (*models.RemoveCacheKeyResponse, error) {
+	if err := d.adminService.RemoveCacheKey(ctx, req.Name, req.Key); err != nil {
+		return nil, fmt.Errorf("unable to remove cache key: %w", err)
+	}
+
+	return &models.RemoveCacheKeyResponse{}, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
+
 	"user-management/internal/entities"
 	"user-management/internal/models"
 	"user-management/internal/services"
@@ -26,29 +28,83 @@ func RegisterAuthDelivery(
 	}
 
 	http_server.Register(server, http.MethodPost, "/auth/login", delivery.Login)
+	http_server.Register(server, http.MethodPost, "/auth/login/mfa", delivery.LoginMFA)
+	http_server.Register(server, http.MethodPost, "/auth/refresh", delivery.Refresh)
+	http_server.Register(server, http.MethodPost, "/auth/logout", delivery.Logout)
+	http_server.Register(server, http.MethodDelete, "/auth/lockouts/{user_name}", delivery.Unlock)
 }
 
 func (d *authDelivery) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
-	if req.UserName == "" {
-		return nil, fmt.Errorf("user must not be empty")
+	result, err := d.authService.Login(ctx, &entities.User{
+		UserName: req.UserName,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Password == "" {
-		return nil, fmt.Errorf("password must not be empty")
+	// the user must complete login by a code, so nothing about the user is returned yet.
+	if result.Tokens == nil {
+		return &models.LoginResponse{
+			MFARequired:        true,
+			ChallengeToken:     result.MFAChallenge,
+			ChallengeExpiresIn: expiresIn(result.MFAChallengeExpiredAt),
+		}, nil
 	}
 
-	user, token, err := d.authService.Login(ctx, &entities.User{
-		UserName: req.UserName,
-		Password: req.Password,
-	})
+	return newLoginResponse(result.User, result.Tokens), nil
+}
+
+func (d *authDelivery) LoginMFA(ctx context.Context, req *models.LoginMFARequest) (*models.LoginResponse, error) {
+	user, tokens, err := d.authService.LoginMFA(ctx, req.ChallengeToken, req.Code)
 	if err != nil {
 		return nil, err
 	}
 
+	return newLoginResponse(user, tokens), nil
+}
+
+func newLoginResponse(user *entities.User, tokens *entities.TokenPair) *models.LoginResponse {
 	return &models.LoginResponse{
-		Name:  user.Name.String,
-		Role:  string(user.Role),
-		ID:    user.ID,
-		Token: token,
+		Name:         user.Name.String,
+		Role:         string(user.Role),
+		ID:           user.ID,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    expiresIn(tokens.AccessTokenExpiredAt),
+	}
+}
+
+func (d *authDelivery) Refresh(ctx context.Context, req *models.RefreshRequest) (*models.RefreshResponse, error) {
+	tokens, err := d.authService.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("unable to refresh token: %w", err)
+	}
+
+	return &models.RefreshResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    expiresIn(tokens.AccessTokenExpiredAt),
 	}, nil
 }
+
+func (d *authDelivery) Logout(ctx context.Context, req *models.LogoutRequest) (*models.LogoutResponse, error) {
+	if err := d.authService.Logout(ctx, req.RefreshToken); err != nil {
+		return nil, fmt.Errorf("unable to logout: %w", err)
+	}
+
+	return &models.LogoutResponse{}, nil
+}
+
+func (d *authDelivery) Unlock(ctx context.Context, req *models.UnlockRequest) (*models.UnlockResponse, error) {
+	if err := d.authService.Unlock(ctx, req.UserName); err != nil {
+		return nil, fmt.Errorf("unable to unlock user: %w", err)
+	}
+
+	return &models.UnlockResponse{}, nil
+}
+
+// expiresIn returns the number of seconds until t.
+func expiresIn(t time.Time) int64 {
+	return int64(time.Until(t).Round(time.Second).Seconds())
+}
@@ -0,0 +1,62 @@
+package deliveries
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"user-management/internal/models"
+	"user-management/internal/services"
+	"user-management/pkg/http_server"
+)
+
+type mfaDelivery struct {
+	server     *http_server.HttpServer
+	mfaService services.MFAService
+}
+
+// RegisterMFADelivery is registration of mfa delivery APIs to http server.
+func RegisterMFADelivery(
+	server *http_server.HttpServer,
+	mfaService services.MFAService,
+) {
+	delivery := &mfaDelivery{
+		server:     server,
+		mfaService: mfaService,
+	}
+
+	http_server.Register(server, http.MethodPost, "/auth/mfa", delivery.Enroll)
+	http_server.Register(server, http.MethodPost, "/auth/mfa/activate", delivery.Activate)
+	http_server.Register(server, http.MethodDelete, "/users/{id}/mfa", delivery.Reset)
+}
+
+func (d *mfaDelivery) Enroll(ctx context.Context, _ *models.EnrollMFARequest) (*models.EnrollMFAResponse, error) {
+	enrollment, err := d.mfaService.Enroll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to enroll mfa: %w", err)
+	}
+
+	return &models.EnrollMFAResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	}, nil
+}
+
+func (d *mfaDelivery) Activate(ctx context.Context, req *models.ActivateMFARequest) (*models.ActivateMFAResponse, error) {
+	codes, err := d.mfaService.Activate(ctx, req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("unable to activate mfa: %w", err)
+	}
+
+	return &models.ActivateMFAResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (d *mfaDelivery) Reset(ctx context.Context, req *models.ResetMFARequest) (*models.ResetMFAResponse, error) {
+	if err := d.mfaService.Reset(ctx, req.ID); err != nil {
+		return nil, fmt.Errorf("unable to reset mfa: %w", err)
+	}
+
+	return &models.ResetMFAResponse{}, nil
+}
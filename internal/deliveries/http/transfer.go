@@ -0,0 +1,47 @@
+package deliveries
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"user-management/internal/entities"
+	"user-management/internal/models"
+	"user-management/internal/services"
+	"user-management/pkg/database"
+	"user-management/pkg/http_server"
+)
+
+type transferDelivery struct {
+	server          *http_server.HttpServer
+	transferService services.TransferService
+}
+
+// RegisterTransferDelivery is registration of transfer delivery APIs to http server.
+func RegisterTransferDelivery(
+	server *http_server.HttpServer,
+	transferService services.TransferService,
+) {
+	delivery := &transferDelivery{
+		server:          server,
+		transferService: transferService,
+	}
+
+	http_server.Register(server, http.MethodPost, "/transfers", delivery.CreateTransfer)
+}
+
+func (d *transferDelivery) CreateTransfer(ctx context.Context, req *models.CreateTransferRequest) (*models.CreateTransferResponse, error) {
+	id, err := d.transferService.Transfer(ctx, &entities.Transfer{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   database.NullString(req.Description),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to transfer: %w", err)
+	}
+
+	return &models.CreateTransferResponse{
+		ID: id,
+	}, nil
+}
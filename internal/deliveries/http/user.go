@@ -2,13 +2,14 @@ package deliveries
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
-	"slices"
 
 	"user-management/internal/entities"
 	"user-management/internal/models"
 	"user-management/internal/services"
+	"user-management/pkg/currency"
 	"user-management/pkg/database"
 	"user-management/pkg/http_server"
 )
@@ -32,6 +33,7 @@ func RegisterUserDelivery(
 	http_server.Register(server, http.MethodPost, "/users", delivery.CreateUser)
 	http_server.Register(server, http.MethodGet, "/users/{id}", delivery.GetUserByID)
 	http_server.Register(server, http.MethodPut, "/users/{id}", delivery.UpdateUser)
+	http_server.Register(server, http.MethodDelete, "/users/{id}", delivery.DeleteUser)
 
 	// for accounts
 	http_server.Register(server, http.MethodGet, "/users/{user_id}/accounts", delivery.ListAccountByUserID)
@@ -39,22 +41,6 @@ func RegisterUserDelivery(
 }
 
 func (d *userDelivery) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.CreateUserResponse, error) {
-	if req.UserName == "" {
-		return nil, fmt.Errorf("user must not be empty")
-	}
-
-	if req.Password == "" {
-		return nil, fmt.Errorf("password must not be empty")
-	}
-
-	if req.Name == "" {
-		return nil, fmt.Errorf("name must not be empty")
-	}
-
-	if !slices.Contains(entities.UserRoleList, entities.User_Role(req.Role)) {
-		return nil, fmt.Errorf("user role is not valid")
-	}
-
 	id, err := d.userService.CreateUser(ctx, &entities.User{
 		UserName: req.UserName,
 		Name:     database.NullString(req.Name),
@@ -71,30 +57,39 @@ func (d *userDelivery) CreateUser(ctx context.Context, req *models.CreateUserReq
 }
 
 func (d *userDelivery) GetUserByID(ctx context.Context, req *models.GetUserByIDRequest) (*models.GetUserByIDResponse, error) {
-	if req.ID == 0 {
-		return nil, fmt.Errorf("id must not be empty")
-	}
-
 	data, err := d.userService.GetUserByID(ctx, req.ID)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve user by id: %w", err)
 	}
 
-	return &models.GetUserByIDResponse{
+	resp := &models.GetUserByIDResponse{
 		ID:         data.ID,
 		Name:       data.Name.String,
 		AccountIDs: data.AccountIDs,
-	}, nil
-}
+	}
 
-func (d *userDelivery) UpdateUser(ctx context.Context, req *models.UpdateUserRequest) (*models.UpdateUserResponse, error) {
-	if req.ID == 0 {
-		return nil, fmt.Errorf("id must not be empty")
+	// net worth is only calculated when client asks for a currency.
+	if req.Currency != "" {
+		code := currency.Normalize(req.Currency)
+		netWorth, err := d.userService.GetNetWorth(ctx, req.ID, code)
+		if err != nil {
+			return nil, fmt.Errorf("unable to calculate net worth: %w", err)
+		}
+		resp.NetWorth = &models.Money{
+			Amount:   netWorth,
+			Currency: code,
+		}
 	}
 
+	return resp, nil
+}
+
+func (d *userDelivery) UpdateUser(ctx context.Context, req *models.UpdateUserRequest) (*models.UpdateUserResponse, error) {
 	if err := d.userService.Update(ctx, &entities.User{
-		ID:   req.ID,
-		Name: database.NullString(req.Name),
+		ID: req.ID,
+		// empty name keeps the current name
+		Name: sql.NullString{String: req.Name, Valid: req.Name != ""},
+		Role: entities.User_Role(req.Role),
 	}); err != nil {
 		return nil, fmt.Errorf("unable to update user by id: %w", err)
 	}
@@ -102,11 +97,20 @@ func (d *userDelivery) UpdateUser(ctx context.Context, req *models.UpdateUserReq
 	return &models.UpdateUserResponse{}, nil
 }
 
-func (d *userDelivery) ListAccountByUserID(ctx context.Context, req *models.ListAccountByUserIDRequest) (*models.ListAccountByUserIDResponse, error) {
-	if req.UserID == 0 {
-		return nil, fmt.Errorf("user id must not be empty")
+func (d *userDelivery) DeleteUser(ctx context.Context, req *models.DeleteUserRequest) (*models.DeleteUserResponse, error) {
+	deleteByID := d.userService.DeleteByID
+	if req.Purge {
+		deleteByID = d.userService.PurgeByID
+	}
+
+	if err := deleteByID(ctx, req.ID); err != nil {
+		return nil, fmt.Errorf("unable to delete user by id: %w", err)
 	}
 
+	return &models.DeleteUserResponse{}, nil
+}
+
+func (d *userDelivery) ListAccountByUserID(ctx context.Context, req *models.ListAccountByUserIDRequest) (*models.ListAccountByUserIDResponse, error) {
 	accounts, err := d.userService.ListAccountByID(ctx, req.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve accounts by user id: %w", err)
@@ -116,9 +120,11 @@ func (d *userDelivery) ListAccountByUserID(ctx context.Context, req *models.List
 
 	for _, a := range accounts {
 		result = append(result, &models.Account{
-			ID:      a.ID,
-			Name:    a.Name.String,
-			Balance: a.Balance.Int64,
+			ID:       a.ID,
+			Name:     a.Name.String,
+			Balance:  a.Balance.Int64,
+			Currency: a.Currency,
+			Status:   string(a.Status),
 		})
 	}
 	res := models.ListAccountByUserIDResponse(result)
@@ -126,14 +132,11 @@ func (d *userDelivery) ListAccountByUserID(ctx context.Context, req *models.List
 }
 
 func (d *userDelivery) CreateAccountByUserID(ctx context.Context, req *models.CreateAccountByUserIDRequest) (*models.CreateAccountByUserIDResponse, error) {
-	if req.UserID == 0 {
-		return nil, fmt.Errorf("user id must not be empty")
-	}
-
 	id, err := d.userService.CreateAccount(ctx, &entities.Account{
-		Name:    database.NullString(req.Name),
-		UserID:  req.UserID,
-		Balance: database.NullInt64(req.Balance),
+		Name:     database.NullString(req.Name),
+		UserID:   req.UserID,
+		Balance:  database.NullInt64(req.Balance),
+		Currency: currency.Normalize(req.Currency),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to update user by id: %w", err)
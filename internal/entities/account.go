@@ -7,6 +7,8 @@ type Account struct {
 	Name      sql.NullString `json:"name" db:"name"`
 	UserID    int64          `json:"user_id" db:"user_id"`
 	Balance   sql.NullInt64  `json:"balance" db:"balance"`
+	Currency  string         `json:"currency" db:"currency"`
+	Status    Account_Status `json:"status" db:"status"`
 	CreatedAt sql.NullTime   `json:"created_at" db:"created_at"`
 	UpdatedAt sql.NullTime   `json:"updated_at" db:"updated_at"`
 }
@@ -14,3 +16,19 @@ type Account struct {
 func (u *Account) TableName() string {
 	return "accounts"
 }
+
+// Account_Status is the representation of an account status enum
+type Account_Status string
+
+const (
+	// ActiveStatus account can send and receive money.
+	ActiveStatus Account_Status = "ACTIVE"
+	// FrozenStatus account can only receive money.
+	FrozenStatus Account_Status = "FROZEN"
+	// ClosedStatus account has zero balance and can not send or receive money anymore.
+	ClosedStatus Account_Status = "CLOSED"
+)
+
+var (
+	AccountStatusList = []Account_Status{ActiveStatus, FrozenStatus, ClosedStatus}
+)
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// RuntimeInfo is a snapshot of the running process.
+type RuntimeInfo struct {
+	StartedAt  time.Time
+	Goroutines int
+	GOMAXPROCS int
+	NumCPU     int
+	// HeapAlloc is the number of bytes of allocated heap objects.
+	HeapAlloc uint64
+	Build     *BuildInfo
+}
+
+// BuildInfo is the information embedded into the binary by go build.
+type BuildInfo struct {
+	GoVersion string
+	Path      string
+	Version   string
+	// Settings are build settings like vcs.revision and vcs.time.
+	Settings map[string]string
+}
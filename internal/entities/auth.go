@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RefreshToken is a single-use token to issue a new access token, only the hash of token is stored.
+type RefreshToken struct {
+	ID        int64        `json:"id" db:"id"`
+	UserID    int64        `json:"user_id" db:"user_id"`
+	FamilyID  int64        `json:"family_id" db:"family_id"`
+	TokenHash string       `json:"token_hash" db:"token_hash"`
+	ExpiredAt time.Time    `json:"expired_at" db:"expired_at"`
+	UsedAt    sql.NullTime `json:"used_at" db:"used_at"`
+	RevokedAt sql.NullTime `json:"revoked_at" db:"revoked_at"`
+	CreatedAt sql.NullTime `json:"created_at" db:"created_at"`
+}
+
+func (t *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// TokenPair is the tokens issued to a user after login or refresh.
+type TokenPair struct {
+	AccessToken          string
+	AccessTokenExpiredAt time.Time
+	RefreshToken         string
+}
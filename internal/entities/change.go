@@ -0,0 +1,22 @@
+package entities
+
+// Change is an event about an entity written by repositories, it is published after the write is committed
+// so data derived from entities (ex: caches) can be kept coherent.
+type Change interface {
+	isChange()
+}
+
+// UserChanged is published when a user is created, updated or deleted.
+type UserChanged struct {
+	ID       int64
+	UserName string
+}
+
+// AccountChanged is published when an account is created, updated (including its balance) or deleted.
+type AccountChanged struct {
+	ID     int64
+	UserID int64
+}
+
+func (UserChanged) isChange()    {}
+func (AccountChanged) isChange() {}
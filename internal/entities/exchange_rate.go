@@ -0,0 +1,15 @@
+package entities
+
+import "database/sql"
+
+// ExchangeRate is a rate that 1 major unit of BaseCurrency equals Rate major units of QuoteCurrency.
+type ExchangeRate struct {
+	BaseCurrency  string       `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string       `json:"quote_currency" db:"quote_currency"`
+	Rate          string       `json:"rate" db:"rate"`
+	UpdatedAt     sql.NullTime `json:"updated_at" db:"updated_at"`
+}
+
+func (e *ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
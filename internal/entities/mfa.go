@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UserMFA is the TOTP enrollment of a user, the secret is sealed and never returned after enrollment.
+type UserMFA struct {
+	UserID       int64        `json:"user_id" db:"user_id"`
+	Secret       string       `json:"secret" db:"secret"`
+	EnabledAt    sql.NullTime `json:"enabled_at" db:"enabled_at"`
+	LastUsedStep int64        `json:"last_used_step" db:"last_used_step"`
+	CreatedAt    sql.NullTime `json:"created_at" db:"created_at"`
+}
+
+func (m *UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a one-time code replacing a TOTP code when the authenticator is lost, only the hash of code is stored.
+type MFARecoveryCode struct {
+	ID        int64        `json:"id" db:"id"`
+	UserID    int64        `json:"user_id" db:"user_id"`
+	CodeHash  string       `json:"code_hash" db:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at" db:"used_at"`
+	CreatedAt sql.NullTime `json:"created_at" db:"created_at"`
+}
+
+func (c *MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAEnrollment is the secret of a new enrollment which is added to an authenticator app.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAChallenge is the payload of a challenge token issued by the password step of login,
+// it is exchanged with a code for tokens. It is encrypted by another key than access tokens,
+// so it can never be used as an access token.
+// ID is random and revoked by a successful exchange, so a challenge token is exchanged once.
+type MFAChallenge struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+func (c *MFAChallenge) Valid() error {
+	if time.Now().After(c.ExpiredAt) {
+		return fmt.Errorf("challenge has been expired")
+	}
+
+	return nil
+}
+
+func (c *MFAChallenge) AddExpired(expirationTime time.Duration) {
+	c.IssuedAt = time.Now()
+	c.ExpiredAt = c.IssuedAt.Add(expirationTime)
+}
+
+// LoginResult is the result of the password step of login, either tokens are issued
+// or a challenge token is issued if the user has enabled MFA.
+type LoginResult struct {
+	User   *User
+	Tokens *TokenPair
+
+	MFAChallenge          string
+	MFAChallengeExpiredAt time.Time
+}
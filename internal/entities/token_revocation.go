@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RevokedToken is an access token revoked by its id (jti) before expiration.
+type RevokedToken struct {
+	TokenID   string       `json:"token_id" db:"token_id"`
+	UserID    int64        `json:"user_id" db:"user_id"`
+	ExpiredAt time.Time    `json:"expired_at" db:"expired_at"`
+	RevokedAt sql.NullTime `json:"revoked_at" db:"revoked_at"`
+}
+
+func (t *RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// UserTokenRevocation revokes all tokens of user which were issued before RevokedBefore.
+type UserTokenRevocation struct {
+	UserID        int64     `json:"user_id" db:"user_id"`
+	RevokedBefore time.Time `json:"revoked_before" db:"revoked_before"`
+}
+
+func (t *UserTokenRevocation) TableName() string {
+	return "user_token_revocations"
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Transaction is a posted entry of an account, it explains how the balance of account was changed.
+type Transaction struct {
+	ID                    int64                 `json:"id" db:"id"`
+	AccountID             int64                 `json:"account_id" db:"account_id"`
+	TransferID            int64                 `json:"transfer_id" db:"transfer_id"`
+	Direction             LedgerEntry_Direction `json:"direction" db:"direction"`
+	Amount                int64                 `json:"amount" db:"amount"`
+	Description           sql.NullString        `json:"description" db:"description"`
+	CounterpartyAccountID sql.NullInt64         `json:"counterparty_account_id" db:"counterparty_account_id"`
+	BalanceAfter          int64                 `json:"balance_after" db:"balance_after"`
+	PostedAt              time.Time             `json:"posted_at" db:"posted_at"`
+}
+
+func (t *Transaction) TableName() string {
+	return "transactions"
+}
+
+// TransactionFilter is the filter for listing transactions of an account.
+// PostedFrom is inclusive and PostedTo is exclusive, a zero value means no bound.
+type TransactionFilter struct {
+	PostedFrom time.Time
+	PostedTo   time.Time
+
+	// pagination by keyset (posted_at, id) of the last item in previous page.
+	AfterPostedAt time.Time
+	AfterID       int64
+	Limit         int
+}
@@ -0,0 +1,48 @@
+package entities
+
+import "database/sql"
+
+type Transfer struct {
+	ID            int64          `json:"id" db:"id"`
+	FromAccountID int64          `json:"from_account_id" db:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id" db:"to_account_id"`
+	Amount        int64          `json:"amount" db:"amount"`
+	Description   sql.NullString `json:"description" db:"description"`
+	CreatedBy     int64          `json:"created_by" db:"created_by"`
+	CreatedAt     sql.NullTime   `json:"created_at" db:"created_at"`
+}
+
+func (t *Transfer) TableName() string {
+	return "transfers"
+}
+
+// LedgerEntry is a single side of a double-entry record, every [Transfer] owns one debit and one credit.
+type LedgerEntry struct {
+	ID         int64                 `json:"id" db:"id"`
+	TransferID int64                 `json:"transfer_id" db:"transfer_id"`
+	AccountID  int64                 `json:"account_id" db:"account_id"`
+	Direction  LedgerEntry_Direction `json:"direction" db:"direction"`
+	Amount     int64                 `json:"amount" db:"amount"`
+	CreatedAt  sql.NullTime          `json:"created_at" db:"created_at"`
+}
+
+func (e *LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// SignedAmount returns the amount that the entry applies to the account balance.
+func (e *LedgerEntry) SignedAmount() int64 {
+	if e.Direction == DebitDirection {
+		return -e.Amount
+	}
+
+	return e.Amount
+}
+
+// LedgerEntry_Direction is the representation of a ledger direction enum
+type LedgerEntry_Direction string
+
+const (
+	DebitDirection  LedgerEntry_Direction = "DEBIT"
+	CreditDirection LedgerEntry_Direction = "CREDIT"
+)
@@ -13,6 +13,7 @@ type User struct {
 	Password  string         `json:"password" db:"password"`
 	Role      User_Role      `json:"role" db:"role"`
 	CreatedBy int64          `json:"created_by" db:"created_by"`
+	DeletedAt sql.NullTime   `json:"deleted_at" db:"deleted_at"`
 }
 
 func (u *User) TableName() string {
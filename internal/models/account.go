@@ -1,9 +1,36 @@
 package models
 
 type GetAccountByIDRequest struct {
-	ID int64
+	ID int64 `json:"id" validate:"required"`
 }
 
 type GetAccountByIDResponse struct {
 	*Account
 }
+
+type UpdateAccountRequest struct {
+	ID     int64  `json:"id" validate:"required"`
+	Name   string `json:"name" validate:"max=128"`
+	Status string `json:"status" validate:"enum=account_status"`
+}
+type UpdateAccountResponse struct {
+}
+
+type DeleteAccountRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+type DeleteAccountResponse struct {
+}
+
+type ListTransactionByAccountIDRequest struct {
+	ID     int64  `json:"id" validate:"required"`
+	Cursor string `json:"cursor"`
+	Limit  int64  `json:"limit" validate:"min=1,max=100"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type ListTransactionByAccountIDResponse struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type GetRuntimeInfoRequest struct {
+}
+type GetRuntimeInfoResponse struct {
+	StartedAt     time.Time  `json:"started_at"`
+	UptimeSeconds int64      `json:"uptime_seconds"`
+	Goroutines    int        `json:"goroutines"`
+	GOMAXPROCS    int        `json:"gomaxprocs"`
+	NumCPU        int        `json:"num_cpu"`
+	HeapAlloc     uint64     `json:"heap_alloc_bytes"`
+	Build         *BuildInfo `json:"build"`
+}
+
+type BuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+type GetConfigRequest struct {
+}
+type GetConfigResponse struct {
+	Config any `json:"config"`
+}
+
+type ListCachesRequest struct {
+}
+type ListCachesResponse struct {
+	Caches []*Cache `json:"caches"`
+}
+
+type Cache struct {
+	Name string `json:"name"`
+	// Size is -1 if the cache can not be counted.
+	Size int `json:"size"`
+}
+
+type PurgeCacheRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+type PurgeCacheResponse struct {
+}
+
+type RemoveCacheKeyRequest struct {
+	Name string `json:"name" validate:"required"`
+	Key  string `json:"key" validate:"required"`
+}
+type RemoveCacheKeyResponse struct {
+}
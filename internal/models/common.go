@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	ID         int64   `json:"id"`
 	Name       string  `json:"name"`
@@ -7,7 +9,25 @@ type User struct {
 }
 
 type Account struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	Balance int64  `json:"balance"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Balance  int64  `json:"balance"`
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+}
+
+// Money is an amount in minor units of the currency (ex: 100 USD cents, 100 JPY).
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type Transaction struct {
+	ID                    int64     `json:"id"`
+	Amount                int64     `json:"amount"`
+	Direction             string    `json:"direction"`
+	Description           string    `json:"description"`
+	CounterpartyAccountID int64     `json:"counterparty_account_id,omitempty"`
+	PostedAt              time.Time `json:"posted_at"`
+	RunningBalance        int64     `json:"running_balance"`
 }
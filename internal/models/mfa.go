@@ -0,0 +1,23 @@
+package models
+
+type EnrollMFARequest struct {
+}
+type EnrollMFAResponse struct {
+	Secret string `json:"secret"`
+	// ProvisioningURI is the otpauth uri which is shown as a QR code to be scanned by authenticator apps.
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type ActivateMFARequest struct {
+	Code string `json:"code" validate:"required,regexp=^[0-9]{6}$"`
+}
+type ActivateMFAResponse struct {
+	// RecoveryCodes are shown only once, each of them replaces a TOTP code once.
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ResetMFARequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+type ResetMFAResponse struct {
+}
@@ -0,0 +1,11 @@
+package models
+
+type CreateTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" validate:"required"`
+	ToAccountID   int64  `json:"to_account_id" validate:"required"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	Description   string `json:"description" validate:"max=256"`
+}
+type CreateTransferResponse struct {
+	ID int64 `json:"id"`
+}
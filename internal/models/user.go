@@ -1,43 +1,55 @@
 package models
 
 type CreateUserRequest struct {
-	UserName string `json:"user_name"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
-	Role     string `json:"role"`
+	UserName string `json:"user_name" validate:"required,max=64"`
+	Password string `json:"password" validate:"required,min=6,max=128"`
+	Name     string `json:"name" validate:"required,max=128"`
+	Role     string `json:"role" validate:"required,enum=user_role"`
 }
 type CreateUserResponse struct {
 	ID int64 `json:"id"`
 }
 
 type GetUserByIDRequest struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID       int64  `json:"id" validate:"required"`
+	Name     string `json:"name"`
+	Currency string `json:"currency" validate:"regexp=^[A-Za-z]{3}$"`
 }
 type GetUserByIDResponse struct {
 	ID         int64   `json:"id"`
 	Name       string  `json:"name"`
 	AccountIDs []int64 `json:"account_ids"`
+	NetWorth   *Money  `json:"net_worth,omitempty"`
 }
 
 type UpdateUserRequest struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" validate:"required"`
+	Name string `json:"name" validate:"max=128"`
+	Role string `json:"role" validate:"enum=user_role"`
 }
 type UpdateUserResponse struct {
 }
 
+type DeleteUserRequest struct {
+	ID int64 `json:"id" validate:"required"`
+	// Purge deletes user permanently instead of soft deleting, only for super admins.
+	Purge bool `json:"purge"`
+}
+type DeleteUserResponse struct {
+}
+
 type CreateAccountByUserIDRequest struct {
-	UserID  int64  `json:"user_id"`
-	Name    string `json:"name"`
-	Balance int64  `json:"balance"`
+	UserID   int64  `json:"user_id" validate:"required"`
+	Name     string `json:"name" validate:"max=128"`
+	Balance  int64  `json:"balance" validate:"min=0"`
+	Currency string `json:"currency" validate:"regexp=^[A-Za-z]{3}$"`
 }
 type CreateAccountByUserIDResponse struct {
 	ID int64 `json:"id"`
 }
 
 type ListAccountByUserIDRequest struct {
-	UserID int64 `json:"user_id"`
+	UserID int64 `json:"user_id" validate:"required"`
 }
 
 type ListAccountByUserIDResponse []*Account
@@ -2,17 +2,31 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"user-management/internal/entities"
 	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
 )
 
 type AccountRepository struct {
+	changes ChangePublisher
 }
 
-func NewAccountRepository() *AccountRepository {
-	return &AccountRepository{}
+// NewAccountRepository creates a repository which publishes [entities.AccountChanged] to changes after writing accounts.
+func NewAccountRepository(changes ChangePublisher) *AccountRepository {
+	return &AccountRepository{
+		changes: changes,
+	}
+}
+
+// ownerNotDeleted returns the condition that the owner of accounts in table is not soft deleted,
+// so accounts of deleted users are read as not found.
+func ownerNotDeleted(table string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.id = %[2]s.user_id AND %[1]s.deleted_at IS NULL)", (&entities.User{}).TableName(), table)
 }
 
 // Create is an implementation of inserting a user entity
@@ -24,13 +38,15 @@ func (r *AccountRepository) Create(ctx context.Context, db database.Executor, da
 	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
 
 	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
-		return err
+		return postgres_client.WrapError(err)
 	}
 
+	publishChange(ctx, r.changes, entities.AccountChanged{ID: data.ID, UserID: data.UserID})
+
 	return nil
 }
 
-// ListAccountByUserID is an implementation of listing user by id from database.
+// ListAccountByUserID is an implementation of listing accounts of user by id from database, none if the user is deleted.
 func (r *AccountRepository) ListAccountByUserID(ctx context.Context, db database.Executor, userID int64) ([]*entities.Account, error) {
 	var result []*entities.Account
 	e := &entities.Account{}
@@ -38,22 +54,22 @@ func (r *AccountRepository) ListAccountByUserID(ctx context.Context, db database
 	stmt := fmt.Sprintf(`
 		SELECT %s
 		FROM %s 
-		WHERE user_id = $1
-	`, strings.Join(fieldNames, ", "), e.TableName())
+		WHERE user_id = $1 AND %s
+	`, strings.Join(fieldNames, ", "), e.TableName(), ownerNotDeleted(e.TableName()))
 	rows, err := db.QueryContext(ctx, stmt, userID)
 	if err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	for rows.Next() {
 		var item entities.Account
 		_, values := database.FieldMap(&item)
 		if err := rows.Scan(values...); err != nil {
-			return nil, err
+			return nil, postgres_client.WrapError(err)
 		}
 
 		result = append(result, &item)
@@ -68,16 +84,135 @@ func (r *AccountRepository) GetAccountByID(ctx context.Context, db database.Exec
 	stmt := fmt.Sprintf(`
 		SELECT %s
 		FROM %s 
-		WHERE id = $1
-	`, strings.Join(fieldNames, ", "), result.TableName())
+		WHERE id = $1 AND %s
+	`, strings.Join(fieldNames, ", "), result.TableName(), ownerNotDeleted(result.TableName()))
+	row := db.QueryRowContext(ctx, stmt, id)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
+
+// GetAccountByIDForUpdate is an implementation of retrieving account by id and locking the row
+// until the end of the transaction.
+func (r *AccountRepository) GetAccountByIDForUpdate(ctx context.Context, db database.Executor, id int64) (*entities.Account, error) {
+	var result entities.Account
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE id = $1 AND %s
+		FOR UPDATE
+	`, strings.Join(fieldNames, ", "), result.TableName(), ownerNotDeleted(result.TableName()))
 	row := db.QueryRowContext(ctx, stmt, id)
 	if err := row.Err(); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	if err := row.Scan(values...); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	return &result, nil
 }
+
+// AddBalanceByID is an implementation of adding amount (can be negative) to the balance of account by id.
+func (r *AccountRepository) AddBalanceByID(ctx context.Context, db database.Executor, id int64, amount int64) error {
+	e := &entities.Account{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET 
+			balance = COALESCE(balance, 0) + $2,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING user_id
+	`, e.TableName())
+
+	return r.writeByID(ctx, db, id, stmt, amount)
+}
+
+// UpdateByID is an implementation of updating name and status of account by id, empty values keep the current ones.
+func (r *AccountRepository) UpdateByID(ctx context.Context, db database.Executor, id int64, data *entities.Account) error {
+	e := &entities.Account{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET 
+			name = COALESCE($2, name),
+			status = COALESCE(NULLIF($3, '')::account_status_type, status),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING user_id
+	`, e.TableName())
+
+	return r.writeByID(ctx, db, id, stmt, data.Name, string(data.Status))
+}
+
+// DeleteByID is an implementation of deleting account by id from database.
+func (r *AccountRepository) DeleteByID(ctx context.Context, db database.Executor, id int64) error {
+	e := &entities.Account{}
+	stmt := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE id = $1
+		RETURNING user_id
+	`, e.TableName())
+
+	return r.writeByID(ctx, db, id, stmt)
+}
+
+// FreezeByUserID is an implementation of freezing all active accounts of user.
+func (r *AccountRepository) FreezeByUserID(ctx context.Context, db database.Executor, userID int64) error {
+	e := &entities.Account{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET 
+			status = $2,
+			updated_at = NOW()
+		WHERE user_id = $1 AND status = $3
+		RETURNING id
+	`, e.TableName())
+
+	rows, err := db.QueryContext(ctx, stmt, userID, entities.FrozenStatus, entities.ActiveStatus)
+	if err != nil {
+		return postgres_client.WrapError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return postgres_client.WrapError(err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	for _, id := range ids {
+		publishChange(ctx, r.changes, entities.AccountChanged{ID: id, UserID: userID})
+	}
+
+	return nil
+}
+
+// writeByID executes stmt writing account by id which returns the user id, and then publishes the change.
+func (r *AccountRepository) writeByID(ctx context.Context, db database.Executor, id int64, stmt string, args ...any) error {
+	var userID int64
+	if err := db.QueryRowContext(ctx, stmt, append([]any{id}, args...)...).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return xerrors.NotFound("no row affected")
+		}
+		return postgres_client.WrapError(err)
+	}
+
+	publishChange(ctx, r.changes, entities.AccountChanged{ID: id, UserID: userID})
+
+	return nil
+}
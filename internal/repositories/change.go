@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"user-management/internal/entities"
+	"user-management/pkg/postgres_client"
+)
+
+// ChangePublisher receives changes of entities written by repositories.
+type ChangePublisher interface {
+	Publish(ctx context.Context, change entities.Change)
+}
+
+// publishChange publishes change after the transaction of ctx is committed (or at once without transaction).
+// A nil publisher drops the change, it is only used by callers which do not write.
+func publishChange(ctx context.Context, publisher ChangePublisher, change entities.Change) {
+	if publisher == nil {
+		return
+	}
+
+	postgres_client.AfterCommit(ctx, func(ctx context.Context) {
+		publisher.Publish(ctx, change)
+	})
+}
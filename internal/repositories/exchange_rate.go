@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type ExchangeRateRepository struct {
+}
+
+func NewExchangeRateRepository() *ExchangeRateRepository {
+	return &ExchangeRateRepository{}
+}
+
+// Upsert is an implementation of inserting or replacing rate of an exchange rate entity
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, db database.Executor, data *entities.ExchangeRate) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(base_currency, quote_currency, rate, updated_at) VALUES($1, $2, $3, NOW())
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE
+		SET 
+			rate = EXCLUDED.rate,
+			updated_at = EXCLUDED.updated_at
+	`, data.TableName())
+	if _, err := db.ExecContext(ctx, stmt, data.BaseCurrency, data.QuoteCurrency, data.Rate); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// GetRate is an implementation of retrieving exchange rate by base and quote currency from database.
+func (r *ExchangeRateRepository) GetRate(ctx context.Context, db database.Executor, base, quote string) (*entities.ExchangeRate, error) {
+	var result entities.ExchangeRate
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE base_currency = $1 AND quote_currency = $2
+	`, strings.Join(fieldNames, ", "), result.TableName())
+	row := db.QueryRowContext(ctx, stmt, base, quote)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type LedgerRepository struct {
+}
+
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{}
+}
+
+// Create is an implementation of inserting a ledger entry entity
+func (r *LedgerRepository) Create(ctx context.Context, db database.Executor, data *entities.LedgerEntry) error {
+	fieldNames, values := database.FieldMap(data)
+	placeHolder := database.GetPlaceholders(len(fieldNames))
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(%s) VALUES(%s)
+	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+
+	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
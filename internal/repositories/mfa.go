@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+type MFARepository struct {
+}
+
+func NewMFARepository() *MFARepository {
+	return &MFARepository{}
+}
+
+// UpsertPending is an implementation of inserting a not enabled enrollment or replacing the secret of it,
+// an enabled enrollment is kept and a conflict error is returned.
+func (r *MFARepository) UpsertPending(ctx context.Context, db database.Executor, data *entities.UserMFA) error {
+	fieldNames, values := database.FieldMap(data)
+	placeHolder := database.GetPlaceholders(len(fieldNames))
+	stmt := fmt.Sprintf(`
+		INSERT INTO %[1]s(%[2]s) VALUES(%[3]s)
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			secret = EXCLUDED.secret,
+			last_used_step = EXCLUDED.last_used_step,
+			created_at = EXCLUDED.created_at
+		WHERE %[1]s.enabled_at IS NULL
+	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+
+	return r.exec(ctx, db, xerrors.Conflict("mfa has been enabled"), stmt, values...)
+}
+
+// GetByUserID is an implementation of retrieving the enrollment of user.
+func (r *MFARepository) GetByUserID(ctx context.Context, db database.Executor, userID int64) (*entities.UserMFA, error) {
+	var result entities.UserMFA
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE user_id = $1
+	`, strings.Join(fieldNames, ", "), result.TableName())
+	row := db.QueryRowContext(ctx, stmt, userID)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
+
+// EnableByUserID is an implementation of enabling the enrollment of user.
+func (r *MFARepository) EnableByUserID(ctx context.Context, db database.Executor, userID int64) error {
+	e := &entities.UserMFA{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET enabled_at = NOW()
+		WHERE user_id = $1 AND enabled_at IS NULL
+	`, e.TableName())
+
+	return r.exec(ctx, db, xerrors.NotFound("no row affected"), stmt, userID)
+}
+
+// UseStepByUserID is an implementation of marking a time step of user as used,
+// it returns a not found error if the step or a later one was already used.
+func (r *MFARepository) UseStepByUserID(ctx context.Context, db database.Executor, userID int64, step int64) error {
+	e := &entities.UserMFA{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`, e.TableName())
+
+	return r.exec(ctx, db, xerrors.NotFound("no row affected"), stmt, userID, step)
+}
+
+// DeleteByUserID is an implementation of deleting the enrollment and recovery codes of user.
+func (r *MFARepository) DeleteByUserID(ctx context.Context, db database.Executor, userID int64) error {
+	e := &entities.UserMFA{}
+	c := &entities.MFARecoveryCode{}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id = $1
+	`, c.TableName()), userID); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	stmt := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id = $1
+	`, e.TableName())
+
+	return r.exec(ctx, db, xerrors.NotFound("no row affected"), stmt, userID)
+}
+
+// ReplaceRecoveryCodes is an implementation of replacing all recovery codes of user.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, db database.Executor, userID int64, codes []*entities.MFARecoveryCode) error {
+	c := &entities.MFARecoveryCode{}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id = $1
+	`, c.TableName()), userID); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	for _, code := range codes {
+		fieldNames, values := database.FieldMap(code)
+		placeHolder := database.GetPlaceholders(len(fieldNames))
+		stmt := fmt.Sprintf(`
+			INSERT INTO %s(%s) VALUES(%s)
+		`, code.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+		if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
+			return postgres_client.WrapError(err)
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode is an implementation of marking an unused recovery code of user as used,
+// it returns a not found error if there is no such unused code.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, db database.Executor, userID int64, codeHash string) error {
+	c := &entities.MFARecoveryCode{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, c.TableName())
+
+	return r.exec(ctx, db, xerrors.NotFound("no row affected"), stmt, userID, codeHash)
+}
+
+// exec executes stmt and returns noRowErr if no row is affected.
+func (r *MFARepository) exec(ctx context.Context, db database.Executor, noRowErr error, stmt string, args ...any) error {
+	result, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	if rowAffected == 0 {
+		return noRowErr
+	}
+
+	return nil
+}
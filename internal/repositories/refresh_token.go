@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type RefreshTokenRepository struct {
+}
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{}
+}
+
+// Create is an implementation of inserting a refresh token entity
+func (r *RefreshTokenRepository) Create(ctx context.Context, db database.Executor, data *entities.RefreshToken) error {
+	fieldNames, values := database.FieldMap(data)
+	placeHolder := database.GetPlaceholders(len(fieldNames))
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(%s) VALUES(%s)
+	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+
+	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// GetByTokenHashForUpdate is an implementation of retrieving refresh token by hash and locking the row
+// until the end of the transaction.
+func (r *RefreshTokenRepository) GetByTokenHashForUpdate(ctx context.Context, db database.Executor, tokenHash string) (*entities.RefreshToken, error) {
+	var result entities.RefreshToken
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, strings.Join(fieldNames, ", "), result.TableName())
+	row := db.QueryRowContext(ctx, stmt, tokenHash)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
+
+// MarkUsedByID is an implementation of marking refresh token by id as used (rotated).
+func (r *RefreshTokenRepository) MarkUsedByID(ctx context.Context, db database.Executor, id int64) error {
+	e := &entities.RefreshToken{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL
+	`, e.TableName())
+
+	if _, err := db.ExecContext(ctx, stmt, id); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// RevokeByFamilyID is an implementation of revoking all refresh tokens in a rotation family.
+func (r *RefreshTokenRepository) RevokeByFamilyID(ctx context.Context, db database.Executor, familyID int64) error {
+	e := &entities.RefreshToken{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, e.TableName())
+
+	if _, err := db.ExecContext(ctx, stmt, familyID); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// RevokeByUserID is an implementation of revoking all refresh tokens of user.
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, db database.Executor, userID int64) error {
+	e := &entities.RefreshToken{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, e.TableName())
+
+	if _, err := db.ExecContext(ctx, stmt, userID); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
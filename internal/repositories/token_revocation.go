@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type TokenRevocationRepository struct {
+}
+
+func NewTokenRevocationRepository() *TokenRevocationRepository {
+	return &TokenRevocationRepository{}
+}
+
+// RevokeToken is an implementation of inserting a revoked token entity, revoking twice is ignored.
+// revoked is false if the token had already been revoked, so a single-use token can be consumed atomically.
+func (r *TokenRevocationRepository) RevokeToken(ctx context.Context, db database.Executor, data *entities.RevokedToken) (revoked bool, err error) {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(token_id, user_id, expired_at, revoked_at) VALUES($1, $2, $3, NOW())
+		ON CONFLICT (token_id) DO NOTHING
+	`, data.TableName())
+	result, err := db.ExecContext(ctx, stmt, data.TokenID, data.UserID, data.ExpiredAt)
+	if err != nil {
+		return false, postgres_client.WrapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, postgres_client.WrapError(err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetRevokedToken is an implementation of retrieving revoked token by token id from database.
+func (r *TokenRevocationRepository) GetRevokedToken(ctx context.Context, db database.Executor, tokenID string) (*entities.RevokedToken, error) {
+	var result entities.RevokedToken
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE token_id = $1
+	`, strings.Join(fieldNames, ", "), result.TableName())
+	row := db.QueryRowContext(ctx, stmt, tokenID)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
+
+// RevokeUserTokens is an implementation of upserting the time that all tokens of user issued before are revoked.
+func (r *TokenRevocationRepository) RevokeUserTokens(ctx context.Context, db database.Executor, data *entities.UserTokenRevocation) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(user_id, revoked_before) VALUES($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(%[1]s.revoked_before, EXCLUDED.revoked_before)
+	`, data.TableName())
+	if _, err := db.ExecContext(ctx, stmt, data.UserID, data.RevokedBefore); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// GetUserTokenRevocation is an implementation of retrieving token revocation of user from database.
+func (r *TokenRevocationRepository) GetUserTokenRevocation(ctx context.Context, db database.Executor, userID int64) (*entities.UserTokenRevocation, error) {
+	var result entities.UserTokenRevocation
+	fieldNames, values := database.FieldMap(&result)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE user_id = $1
+	`, strings.Join(fieldNames, ", "), result.TableName())
+	row := db.QueryRowContext(ctx, stmt, userID)
+	if err := row.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	if err := row.Scan(values...); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return &result, nil
+}
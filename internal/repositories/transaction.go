@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type TransactionRepository struct {
+}
+
+func NewTransactionRepository() *TransactionRepository {
+	return &TransactionRepository{}
+}
+
+// Create is an implementation of inserting a transaction entity
+func (r *TransactionRepository) Create(ctx context.Context, db database.Executor, data *entities.Transaction) error {
+	fieldNames, values := database.FieldMap(data)
+	placeHolder := database.GetPlaceholders(len(fieldNames))
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(%s) VALUES(%s)
+	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+
+	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
+
+// ListTransactionByAccountID is an implementation of listing transactions of account from database,
+// newest first and paginated by keyset (posted_at, id).
+func (r *TransactionRepository) ListTransactionByAccountID(ctx context.Context, db database.Executor, accountID int64, filter *entities.TransactionFilter) ([]*entities.Transaction, error) {
+	var result []*entities.Transaction
+	e := &entities.Transaction{}
+	fieldNames, _ := database.FieldMap(e)
+
+	conditions := []string{"account_id = $1"}
+	args := []any{accountID}
+	addCondition := func(cond string, values ...any) {
+		placeholders := make([]any, 0, len(values))
+		for _, v := range values {
+			args = append(args, v)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf(cond, placeholders...))
+	}
+
+	if !filter.PostedFrom.IsZero() {
+		addCondition("posted_at >= %s", filter.PostedFrom)
+	}
+	if !filter.PostedTo.IsZero() {
+		addCondition("posted_at < %s", filter.PostedTo)
+	}
+	if filter.AfterID != 0 {
+		addCondition("(posted_at, id) < (%s, %s)", filter.AfterPostedAt, filter.AfterID)
+	}
+	args = append(args, filter.Limit)
+
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM %s 
+		WHERE %s
+		ORDER BY posted_at DESC, id DESC
+		LIMIT $%d
+	`, strings.Join(fieldNames, ", "), e.TableName(), strings.Join(conditions, " AND "), len(args))
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entities.Transaction
+		_, values := database.FieldMap(&item)
+		if err := rows.Scan(values...); err != nil {
+			return nil, postgres_client.WrapError(err)
+		}
+
+		result = append(result, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, postgres_client.WrapError(err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+)
+
+type TransferRepository struct {
+}
+
+func NewTransferRepository() *TransferRepository {
+	return &TransferRepository{}
+}
+
+// Create is an implementation of inserting a transfer entity
+func (r *TransferRepository) Create(ctx context.Context, db database.Executor, data *entities.Transfer) error {
+	fieldNames, values := database.FieldMap(data)
+	placeHolder := database.GetPlaceholders(len(fieldNames))
+	stmt := fmt.Sprintf(`
+		INSERT INTO %s(%s) VALUES(%s)
+	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
+
+	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	return nil
+}
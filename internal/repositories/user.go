@@ -2,17 +2,27 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"user-management/internal/entities"
 	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
+	changes ChangePublisher
 }
 
-func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+// NewUserRepository creates a repository which publishes [entities.UserChanged] to changes after writing users.
+func NewUserRepository(changes ChangePublisher) *UserRepository {
+	return &UserRepository{
+		changes: changes,
+	}
 }
 
 // Create is an implementation of inserting a user entity
@@ -23,29 +33,51 @@ func (r *UserRepository) Create(ctx context.Context, db database.Executor, data
 		INSERT INTO %s(%s) VALUES(%s)
 	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
 	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
-		return err
+		return postgres_client.WrapError(err)
 	}
 
+	publishChange(ctx, r.changes, entities.UserChanged{ID: data.ID, UserName: data.UserName})
+
 	return nil
 }
 
-// Create is an implementation of inserting a user entity
+// Upsert is an implementation of inserting a user entity if the user name is not used by another user.
 func (r *UserRepository) Upsert(ctx context.Context, db database.Executor, data *entities.User) error {
 	fieldNames, values := database.FieldMap(data)
 	placeHolder := database.GetPlaceholders(len(fieldNames))
 	stmt := fmt.Sprintf(`
 		INSERT INTO %s(%s) VALUES(%s)
-		ON CONFLICT ON CONSTRAINT users_user_name_key DO NOTHING
+		ON CONFLICT (user_name) WHERE deleted_at IS NULL DO NOTHING
 	`, data.TableName(), strings.Join(fieldNames, ", "), placeHolder)
-	if _, err := db.ExecContext(ctx, stmt, values...); err != nil {
-		return err
+	result, err := db.ExecContext(ctx, stmt, values...)
+	if err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return postgres_client.WrapError(err)
+	}
+
+	// nothing changed if the user name is used.
+	if rowAffected > 0 {
+		publishChange(ctx, r.changes, entities.UserChanged{ID: data.ID, UserName: data.UserName})
 	}
 
 	return nil
 }
 
-// GetUserByID is an implementation of retrieving user by id from database.
+// GetUserByID is an implementation of retrieving user by id from database, deleted users are not found.
 func (r *UserRepository) GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error) {
+	return r.getUserByID(ctx, db, id, false)
+}
+
+// GetUserByIDWithDeleted is an implementation of retrieving user by id from database including deleted users.
+func (r *UserRepository) GetUserByIDWithDeleted(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error) {
+	return r.getUserByID(ctx, db, id, true)
+}
+
+func (r *UserRepository) getUserByID(ctx context.Context, db database.Executor, id int64, withDeleted bool) (*entities.UserWithAccounts, error) {
 	userE := entities.User{}
 	accountE := entities.Account{}
 	fieldNames, _ := database.FieldMap(&userE)
@@ -55,20 +87,20 @@ func (r *UserRepository) GetUserByID(ctx context.Context, db database.Executor,
 		FILTER(WHERE %[3]s.id IS NOT NULL) 
 		FROM %[2]s 
 		LEFT JOIN %[3]s ON %[2]s.id = %[3]s.user_id
-		WHERE %[2]s.id = $1
+		WHERE %[2]s.id = $1 AND ($2 OR %[2]s.deleted_at IS NULL)
 		GROUP BY %[2]s.id
 	`, strings.Join(fieldNames, ", users."), userE.TableName(), accountE.TableName())
 
 	var result entities.UserWithAccounts
-	row := db.QueryRowContext(ctx, stmt, id)
+	row := db.QueryRowContext(ctx, stmt, id, withDeleted)
 	if err := row.Err(); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	_, values := database.FieldMap(&result.User)
 
 	if err := row.Scan(append(values, &result.AccountIDs)...); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	return &result, nil
@@ -81,15 +113,15 @@ func (r *UserRepository) GetUserByUserName(ctx context.Context, db database.Exec
 	stmt := fmt.Sprintf(`
 		SELECT %s
 		FROM %s 
-		WHERE user_name = $1
+		WHERE user_name = $1 AND deleted_at IS NULL
 	`, strings.Join(fieldNames, ", "), result.TableName())
 	row := db.QueryRowContext(ctx, stmt, userName)
 	if err := row.Err(); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	if err := row.Scan(values...); err != nil {
-		return nil, err
+		return nil, postgres_client.WrapError(err)
 	}
 
 	return &result, nil
@@ -102,49 +134,105 @@ func (r *UserRepository) UpdateByID(ctx context.Context, db database.Executor, i
 		UPDATE %s
 		SET 
 			name = COALESCE($2, name),
+			role = COALESCE(NULLIF($3, '')::role_type, role),
 			updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING user_name
 	`, e.TableName())
 
-	result, err := db.ExecContext(ctx, stmt, id, data.Name)
-	if err != nil {
-		return err
+	return r.writeByID(ctx, db, id, stmt, data.Name, string(data.Role))
+}
+
+// ReplacePasswordByID is an implementation of replacing the password hash of user by id from database,
+// the hash is only replaced if it is still oldPassword, so a concurrent change of password is kept.
+func (r *UserRepository) ReplacePasswordByID(ctx context.Context, db database.Executor, id int64, oldPassword, newPassword string) error {
+	e := &entities.User{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET 
+			password = $3,
+			updated_at = NOW()
+		WHERE id = $1 AND password = $2 AND deleted_at IS NULL
+		RETURNING user_name
+	`, e.TableName())
+
+	return r.writeByID(ctx, db, id, stmt, oldPassword, newPassword)
+}
+
+// DeleteByID is an implementation of soft deleting user by id from database.
+func (r *UserRepository) DeleteByID(ctx context.Context, db database.Executor, id int64) error {
+	e := &entities.User{}
+	stmt := fmt.Sprintf(`
+		UPDATE %s
+		SET 
+			deleted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING user_name
+	`, e.TableName())
+
+	return r.writeByID(ctx, db, id, stmt)
+}
+
+// PurgeByID is an implementation of permanently deleting user by id from database, including a soft deleted user.
+// Accounts of user are deleted by cascade.
+func (r *UserRepository) PurgeByID(ctx context.Context, db database.Executor, id int64) error {
+	e := &entities.User{}
+	a := &entities.Account{}
+
+	// accounts are deleted by postgres, so their ids are read before to publish changes of them.
+	var accountIDs pq.Int64Array
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT ARRAY(SELECT id FROM %s WHERE user_id = $1)
+	`, a.TableName()), id).Scan(&accountIDs); err != nil {
+		return postgres_client.WrapError(err)
 	}
 
-	rowAffected, err := result.RowsAffected()
+	stmt := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE id = $1
+		RETURNING user_name
+	`, e.TableName())
 
-	if err != nil {
+	if err := r.writeByID(ctx, db, id, stmt); err != nil {
 		return err
 	}
 
-	if rowAffected == 0 {
-		return fmt.Errorf("no row affected")
+	for _, accountID := range accountIDs {
+		publishChange(ctx, r.changes, entities.AccountChanged{ID: accountID, UserID: id})
 	}
 
 	return nil
 }
 
-// DeleteByID is an implementation of deleting user by id from database.
-func (r *UserRepository) DeleteByID(ctx context.Context, db database.Executor, id int64) error {
+// CountByRole is an implementation of counting users which are not deleted by role.
+func (r *UserRepository) CountByRole(ctx context.Context, db database.Executor, role entities.User_Role) (int64, error) {
 	e := &entities.User{}
 	stmt := fmt.Sprintf(`
-		DELETE FROM %s
-		WHERE id = $1
+		SELECT COUNT(*)
+		FROM %s 
+		WHERE role = $1 AND deleted_at IS NULL
 	`, e.TableName())
 
-	result, err := db.ExecContext(ctx, stmt, id)
-	if err != nil {
-		return err
+	var result int64
+	if err := db.QueryRowContext(ctx, stmt, role).Scan(&result); err != nil {
+		return 0, postgres_client.WrapError(err)
 	}
-	rowAffected, err := result.RowsAffected()
 
-	if err != nil {
-		return err
-	}
+	return result, nil
+}
 
-	if rowAffected == 0 {
-		return fmt.Errorf("no row affected")
+// writeByID executes stmt writing user by id which returns the user name, and then publishes the change.
+func (r *UserRepository) writeByID(ctx context.Context, db database.Executor, id int64, stmt string, args ...any) error {
+	var userName string
+	if err := db.QueryRowContext(ctx, stmt, append([]any{id}, args...)...).Scan(&userName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return xerrors.NotFound("no row affected")
+		}
+		return postgres_client.WrapError(err)
 	}
 
+	publishChange(ctx, r.changes, entities.UserChanged{ID: id, UserName: userName})
+
 	return nil
 }
@@ -7,45 +7,220 @@ import (
 	"user-management/internal/repositories"
 	"user-management/pkg/cache"
 	"user-management/pkg/database"
+	"user-management/pkg/http_server/xcontext"
 	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
 )
 
 // AccountService is a service exporter to account for other layers.
 type AccountService interface {
 	GetAccountByID(context.Context, int64) (*entities.Account, error)
+	ListTransactionByAccountID(ctx context.Context, id int64, filter *entities.TransactionFilter) ([]*entities.Transaction, bool, error)
+	Update(ctx context.Context, data *entities.Account) error
+	DeleteByID(ctx context.Context, id int64) error
 }
 
 type accountService struct {
-	pgClient     *postgres_client.PostgresClient
-	accountCache cache.Cache[int64, *entities.Account]
+	pgClient *postgres_client.PostgresClient
+	// cached values are removed by changes of repositories.
+	accountCache cache.LoadingCache[int64, *entities.Account]
+
+	policy *ownershipPolicy
 
 	accountRepo interface {
 		GetAccountByID(ctx context.Context, db database.Executor, id int64) (*entities.Account, error)
+		GetAccountByIDForUpdate(ctx context.Context, db database.Executor, id int64) (*entities.Account, error)
+		UpdateByID(ctx context.Context, db database.Executor, id int64, data *entities.Account) error
+		DeleteByID(ctx context.Context, db database.Executor, id int64) error
+	}
+	transactionRepo interface {
+		ListTransactionByAccountID(ctx context.Context, db database.Executor, accountID int64, filter *entities.TransactionFilter) ([]*entities.Transaction, error)
 	}
 }
 
 func NewAccountService(
 	pgClient *postgres_client.PostgresClient,
-	accountCache cache.Cache[int64, *entities.Account],
+	accountCache cache.LoadingCache[int64, *entities.Account],
+	changes repositories.ChangePublisher,
 ) AccountService {
 	return &accountService{
-		pgClient:     pgClient,
-		accountCache: accountCache,
-		accountRepo:  repositories.NewAccountRepository(),
+		pgClient:        pgClient,
+		accountCache:    accountCache,
+		policy:          newOwnershipPolicy(),
+		accountRepo:     repositories.NewAccountRepository(changes),
+		transactionRepo: repositories.NewTransactionRepository(),
 	}
 }
 
+// GetAccountByID is implementation to business logic for getting account which the user in context is allowed to access.
 func (s *accountService) GetAccountByID(ctx context.Context, id int64) (*entities.Account, error) {
-	if account, err := s.accountCache.Get(ctx, id); err == nil {
-		return account, nil
+	account, err := s.getAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	account, err := s.accountRepo.GetAccountByID(ctx, s.pgClient, id)
+	if err := s.policy.authorizeAccount(ctx, s.pgClient, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *accountService) getAccountByID(ctx context.Context, id int64) (*entities.Account, error) {
+	account, err := s.accountCache.GetOrLoad(ctx, id, func(ctx context.Context) (*entities.Account, error) {
+		return s.accountRepo.GetAccountByID(ctx, s.pgClient, id)
+	})
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("account does not exists").WithReason(reasonAccountNotFound)
+		}
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// ListTransactionByAccountID is implementation to business logic for listing posted transactions of account.
+// It returns true as the second value if there are more transactions after the returned page.
+func (s *accountService) ListTransactionByAccountID(ctx context.Context, id int64, filter *entities.TransactionFilter) ([]*entities.Transaction, bool, error) {
+	// checking account existed and accessible
+	if _, err := s.GetAccountByID(ctx, id); err != nil {
+		return nil, false, err
+	}
+
+	// a missing limit takes the default and a limit above the maximum is clamped to it.
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultTransactionPageSize
+	case filter.Limit > maxTransactionPageSize:
+		filter.Limit = maxTransactionPageSize
+	}
+
+	// fetch one more item to know whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+	transactions, err := s.transactionRepo.ListTransactionByAccountID(ctx, s.pgClient, id, filter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(transactions) > limit {
+		return transactions[:limit], true, nil
+	}
+
+	return transactions, false, nil
+}
+
+// Update is implementation to business logic for renaming account or changing its status.
+// Empty name or status keeps the current one.
+func (s *accountService) Update(ctx context.Context, data *entities.Account) error {
+	return s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		// lock the account, so its balance can not be changed by transfers until the status changed.
+		account, err := s.getAccountByIDForUpdate(ctx, tx, data.ID)
+		if err != nil {
+			return err
+		}
+
+		if data.Status != "" && data.Status != account.Status {
+			if err := checkStatusTransition(ctx, account, data.Status); err != nil {
+				return err
+			}
+		}
+
+		return s.accountRepo.UpdateByID(ctx, tx, data.ID, data)
+	})
+}
+
+// DeleteByID is implementation to business logic for deleting account by id.
+// Only accounts with zero balance and without any transfer can be deleted, others should be closed instead.
+func (s *accountService) DeleteByID(ctx context.Context, id int64) error {
+	return s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		account, err := s.getAccountByIDForUpdate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if account.Balance.Int64 != 0 {
+			return xerrors.Conflict("account balance must be zero").WithReason(reasonAccountBalanceNotZero)
+		}
+
+		if err := s.accountRepo.DeleteByID(ctx, tx, id); err != nil {
+			// transfers and ledger entries are kept forever, so they still reference the account.
+			if xerrors.IsKind(err, xerrors.KindInvalidArgument) {
+				return xerrors.Conflict("account has transfers, close it instead").WithReason(reasonAccountHasTransfers)
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// getAccountByIDForUpdate returns the locked account which the user in context is allowed to access.
+func (s *accountService) getAccountByIDForUpdate(ctx context.Context, db database.Executor, id int64) (*entities.Account, error) {
+	account, err := s.accountRepo.GetAccountByIDForUpdate(ctx, db, id)
 	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("account does not exists").WithReason(reasonAccountNotFound)
+		}
 		return nil, err
 	}
 
-	s.accountCache.Add(ctx, id, account)
+	if err := s.policy.authorizeAccount(ctx, db, account); err != nil {
+		return nil, err
+	}
 
 	return account, nil
 }
+
+// checkStatusTransition returns an error if account can not be changed to the given status by the user in context.
+// Closed is the final status and only accounts with zero balance can be closed.
+// Only admins can freeze or unfreeze accounts, so an owner can not lift a freeze (ex: of a deleted user).
+func checkStatusTransition(ctx context.Context, account *entities.Account, status entities.Account_Status) error {
+	if account.Status == entities.ClosedStatus {
+		return xerrors.Conflict("account is closed").WithReason(reasonAccountClosed)
+	}
+
+	if account.Status == entities.FrozenStatus || status == entities.FrozenStatus {
+		userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		if role := entities.User_Role(userCtx.Role); role != entities.SuperAdminRole && role != entities.AdminRole {
+			return xerrors.PermissionDenied("only admins can change status from %s to %s", account.Status, status).WithReason(reasonAccountStatusDenied)
+		}
+	}
+
+	if status == entities.ClosedStatus && account.Balance.Int64 != 0 {
+		return xerrors.Conflict("account balance must be zero to close").WithReason(reasonAccountBalanceNotZero)
+	}
+
+	return nil
+}
+
+// checkCanSend returns an error if money can not be moved out of account.
+func checkCanSend(account *entities.Account) error {
+	switch account.Status {
+	case entities.FrozenStatus:
+		return xerrors.Conflict("account %d is frozen", account.ID).WithReason(reasonAccountFrozen)
+	case entities.ClosedStatus:
+		return xerrors.Conflict("account %d is closed", account.ID).WithReason(reasonAccountClosed)
+	}
+
+	return nil
+}
+
+// checkCanReceive returns an error if money can not be moved into account.
+func checkCanReceive(account *entities.Account) error {
+	if account.Status == entities.ClosedStatus {
+		return xerrors.Conflict("account %d is closed", account.ID).WithReason(reasonAccountClosed)
+	}
+
+	return nil
+}
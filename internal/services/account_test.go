@@ -0,0 +1,47 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"user-management/internal/entities"
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	const ownerID = 1
+
+	tests := []struct {
+		name     string
+		role     entities.User_Role
+		from, to entities.Account_Status
+		balance  int64
+		wantKind xerrors.Kind
+		wantErr  bool
+	}{
+		{name: "owner closes an active account", role: entities.UserRole, from: entities.ActiveStatus, to: entities.ClosedStatus},
+		{name: "owner is refused to freeze", role: entities.UserRole, from: entities.ActiveStatus, to: entities.FrozenStatus, wantErr: true, wantKind: xerrors.KindPermissionDenied},
+		{name: "owner is refused to unfreeze", role: entities.UserRole, from: entities.FrozenStatus, to: entities.ActiveStatus, wantErr: true, wantKind: xerrors.KindPermissionDenied},
+		{name: "owner is refused to close a frozen account", role: entities.UserRole, from: entities.FrozenStatus, to: entities.ClosedStatus, wantErr: true, wantKind: xerrors.KindPermissionDenied},
+		{name: "admin freezes", role: entities.AdminRole, from: entities.ActiveStatus, to: entities.FrozenStatus},
+		{name: "super admin unfreezes", role: entities.SuperAdminRole, from: entities.FrozenStatus, to: entities.ActiveStatus},
+		{name: "closed is final", role: entities.SuperAdminRole, from: entities.ClosedStatus, to: entities.ActiveStatus, wantErr: true, wantKind: xerrors.KindConflict},
+		{name: "balance must be zero to close", role: entities.UserRole, from: entities.ActiveStatus, to: entities.ClosedStatus, balance: 100, wantErr: true, wantKind: xerrors.KindConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &entities.Account{ID: 10, UserID: ownerID, Status: tt.from, Balance: sql.NullInt64{Int64: tt.balance, Valid: true}}
+
+			err := checkStatusTransition(withUser(ownerID, tt.role), account, tt.to)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, xerrors.IsKind(err, tt.wantKind), err)
+		})
+	}
+}
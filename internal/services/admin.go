@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/pkg/cache"
+	"user-management/pkg/logger"
+)
+
+// AdminService is a service exporter to used for the admin listener.
+type AdminService interface {
+	GetRuntimeInfo(ctx context.Context) (*entities.RuntimeInfo, error)
+	GetConfig(ctx context.Context) (any, error)
+
+	// for caches
+	ListCaches(ctx context.Context) ([]*cache.Info, error)
+	PurgeCache(ctx context.Context, name string) error
+	RemoveCacheKey(ctx context.Context, name, key string) error
+}
+
+// adminService is a representation of service that implements operations of the running process.
+type adminService struct {
+	caches *cache.Registry
+	// config is served as is, so it must not contain secrets.
+	config    any
+	startedAt time.Time
+	build     *entities.BuildInfo
+}
+
+// NewAdminService creates the admin service managing caches, config must already be redacted.
+func NewAdminService(caches *cache.Registry, config any) AdminService {
+	return &adminService{
+		caches:    caches,
+		config:    config,
+		startedAt: time.Now(),
+		build:     readBuildInfo(),
+	}
+}
+
+// GetRuntimeInfo returns the build of binary and the current state of go runtime.
+func (s *adminService) GetRuntimeInfo(_ context.Context) (*entities.RuntimeInfo, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return &entities.RuntimeInfo{
+		StartedAt:  s.startedAt,
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		HeapAlloc:  mem.HeapAlloc,
+		Build:      s.build,
+	}, nil
+}
+
+// GetConfig returns the redacted configuration of the running process.
+func (s *adminService) GetConfig(_ context.Context) (any, error) {
+	return s.config, nil
+}
+
+// ListCaches returns all registered caches with their sizes.
+func (s *adminService) ListCaches(ctx context.Context) ([]*cache.Info, error) {
+	return s.caches.List(ctx)
+}
+
+// PurgeCache removes all values of a cache.
+func (s *adminService) PurgeCache(ctx context.Context, name string) error {
+	if err := s.caches.Purge(ctx, name); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Warn("cache purged by admin", "cache", name)
+
+	return nil
+}
+
+// RemoveCacheKey removes a single key from a cache, the key is parsed to the key type of cache.
+func (s *adminService) RemoveCacheKey(ctx context.Context, name, key string) error {
+	if err := s.caches.Remove(ctx, name, key); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("cache key removed by admin", "cache", name, "key", key)
+
+	return nil
+}
+
+// readBuildInfo returns the build info embedded into the binary, it is empty for binaries built without module support.
+func readBuildInfo() *entities.BuildInfo {
+	info := &entities.BuildInfo{
+		GoVersion: runtime.Version(),
+		Settings:  make(map[string]string),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Path = build.Main.Path
+	info.Version = build.Main.Version
+	for _, setting := range build.Settings {
+		info.Settings[setting.Key] = setting.Value
+	}
+
+	return info
+}
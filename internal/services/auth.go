@@ -2,9 +2,8 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"fmt"
+	"sync"
 	"time"
 
 	"user-management/internal/entities"
@@ -14,72 +13,501 @@ import (
 	"user-management/pkg/database"
 	"user-management/pkg/http_server/xcontext"
 	"user-management/pkg/id_utils"
+	"user-management/pkg/lockout"
+	"user-management/pkg/logger"
 	"user-management/pkg/postgres_client"
 	"user-management/pkg/token_utils"
+	"user-management/pkg/xerrors"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// refreshTokenSize is the number of random bytes of a refresh token.
+const refreshTokenSize = 32
+
+// challengeIDSize is the number of random bytes of the id of a mfa challenge.
+const challengeIDSize = 16
+
+// dummyPassword is hashed once to be verified against passwords of unknown usernames.
+const dummyPassword = "dummy password of unknown usernames"
+
 // AuthService is a auth service exporter to used for other layers.
 type AuthService interface {
-	Login(context.Context, *entities.User) (*entities.User, string, error)
+	// Login verifies the password of user, tokens are issued unless the user has enabled MFA,
+	// then a challenge token is issued to be exchanged by [AuthService.LoginMFA].
+	Login(context.Context, *entities.User) (*entities.LoginResult, error)
+	LoginMFA(ctx context.Context, challengeToken, code string) (*entities.User, *entities.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*entities.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	// Unlock forgets failed logins of userName, so it is not locked out anymore.
+	Unlock(ctx context.Context, userName string) error
 }
 
 // authService is a representation of service that implements business logic for auth domain.
 type authService struct {
-	pgClient    *postgres_client.PostgresClient
-	idGenerator id_utils.IDGenerator
+	pgClient       *postgres_client.PostgresClient
+	idGenerator    id_utils.IDGenerator
+	passwordHasher crypto_utils.PasswordHasher
+
+	tknGenerator    token_utils.Authenticator[*xcontext.UserInfo]
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 
-	tknGenerator token_utils.Authenticator[*xcontext.UserInfo]
+	// challengeGenerator issues challenge tokens of login by a key only used for them.
+	challengeGenerator token_utils.Authenticator[*entities.MFAChallenge]
+	challengeTTL       time.Duration
+	mfaService         MFAService
 
-	userByUserNameCache cache.Cache[string, *entities.User]
+	userByUserNameCache cache.LoadingCache[string, *entities.User]
+
+	// tokenRevocationService revokes the access token presented by logout.
+	tokenRevocationService TokenRevocationService
+
+	// userLockout and ipLockout track failed logins by username and by client ip,
+	// the ip lockout stops guessing passwords of many usernames from a client.
+	// Failures of unknown usernames are tracked by unknownUserLockout, so flooding unknown usernames
+	// can not evict failures of existing users.
+	userLockout        *lockout.Tracker
+	unknownUserLockout *lockout.Tracker
+	ipLockout          *lockout.Tracker
+	// dummyHash is verified when the username is unknown, so the response time does not tell whether it exists.
+	dummyHash func() (string, error)
+
+	// loginAttempts counts logins by result, "success", "failure", "throttled" or "mfa_required".
+	loginAttempts *prometheus.CounterVec
 
 	userRepo interface {
+		GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error)
 		GetUserByUserName(ctx context.Context, db database.Executor, authName string) (*entities.User, error)
+		ReplacePasswordByID(ctx context.Context, db database.Executor, id int64, oldPassword, newPassword string) error
+	}
+	refreshTokenRepo interface {
+		Create(ctx context.Context, db database.Executor, data *entities.RefreshToken) error
+		GetByTokenHashForUpdate(ctx context.Context, db database.Executor, tokenHash string) (*entities.RefreshToken, error)
+		MarkUsedByID(ctx context.Context, db database.Executor, id int64) error
+		RevokeByFamilyID(ctx context.Context, db database.Executor, familyID int64) error
+	}
+	// exchanged mfa challenges are revoked like access tokens, until they expire.
+	tokenRevocationRepo interface {
+		RevokeToken(ctx context.Context, db database.Executor, data *entities.RevokedToken) (bool, error)
 	}
 }
 
-func NewAuthService(
-	pgClient *postgres_client.PostgresClient,
-	idGenerator id_utils.IDGenerator,
-	tknGenerator token_utils.Authenticator[*xcontext.UserInfo],
-	userByUserNameCache cache.Cache[string, *entities.User],
+// AuthServiceDeps are dependencies of [NewAuthService].
+type AuthServiceDeps struct {
+	PGClient       *postgres_client.PostgresClient
+	IDGenerator    id_utils.IDGenerator
+	PasswordHasher crypto_utils.PasswordHasher
+
+	TokenGenerator  token_utils.Authenticator[*xcontext.UserInfo]
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// ChallengeGenerator issues challenge tokens of login, its key must differ from the key of TokenGenerator.
+	ChallengeGenerator token_utils.Authenticator[*entities.MFAChallenge]
+	ChallengeTTL       time.Duration
+	MFAService         MFAService
+
+	UserByUserNameCache    cache.LoadingCache[string, *entities.User]
+	TokenRevocationService TokenRevocationService
+
+	// UserLockout and UnknownUserLockout must not share their counter and locks,
+	// IPLockout may share them with UserLockout by another prefix.
+	UserLockout        *lockout.Tracker
+	UnknownUserLockout *lockout.Tracker
+	IPLockout          *lockout.Tracker
+
+	Changes    repositories.ChangePublisher
+	Registerer prometheus.Registerer
+}
+
+func NewAuthService(deps AuthServiceDeps) AuthService {
+	loginAttempts := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Number of login attempts by result.",
+	}, []string{"result"})
+	deps.Registerer.MustRegister(loginAttempts)
 
-) AuthService {
 	return &authService{
-		pgClient:     pgClient,
-		idGenerator:  idGenerator,
-		tknGenerator: tknGenerator,
+		pgClient:        deps.PGClient,
+		idGenerator:     deps.IDGenerator,
+		passwordHasher:  deps.PasswordHasher,
+		tknGenerator:    deps.TokenGenerator,
+		accessTokenTTL:  deps.AccessTokenTTL,
+		refreshTokenTTL: deps.RefreshTokenTTL,
 
-		userByUserNameCache: userByUserNameCache,
+		challengeGenerator: deps.ChallengeGenerator,
+		challengeTTL:       deps.ChallengeTTL,
+		mfaService:         deps.MFAService,
+
+		userByUserNameCache:    deps.UserByUserNameCache,
+		tokenRevocationService: deps.TokenRevocationService,
+		userLockout:            deps.UserLockout,
+		unknownUserLockout:     deps.UnknownUserLockout,
+		ipLockout:              deps.IPLockout,
+		dummyHash: sync.OnceValues(func() (string, error) {
+			return deps.PasswordHasher.Hash(dummyPassword)
+		}),
+		loginAttempts: loginAttempts,
 
 		// for repositories
-		userRepo: repositories.NewUserRepository(),
+		// passwords are rehashed on login, so cached users are removed by changes.
+		userRepo:            repositories.NewUserRepository(deps.Changes),
+		refreshTokenRepo:    repositories.NewRefreshTokenRepository(),
+		tokenRevocationRepo: repositories.NewTokenRevocationRepository(),
+	}
+}
+
+func (s *authService) Login(ctx context.Context, req *entities.User) (*entities.LoginResult, error) {
+	result, err := s.login(ctx, req)
+	if err != nil {
+		s.countFailedLogin(err)
+		return nil, err
+	}
+
+	if result.Tokens == nil {
+		s.loginAttempts.WithLabelValues("mfa_required").Inc()
+		return result, nil
+	}
+	s.loginAttempts.WithLabelValues("success").Inc()
+
+	return result, nil
+}
+
+func (s *authService) login(ctx context.Context, req *entities.User) (*entities.LoginResult, error) {
+	ip := xcontext.ExtractClientIPFromContext(ctx)
+	if err := s.checkLockout(ctx, req.UserName, ip); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userByUserNameCache.GetOrLoad(ctx, req.UserName, func(ctx context.Context) (*entities.User, error) {
+		return s.userRepo.GetUserByUserName(ctx, s.pgClient, req.UserName)
+	})
+	if err != nil {
+		if !xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, err
+		}
+
+		// a password is verified anyway, so unknown usernames take as long as wrong passwords.
+		if hashed, err := s.dummyHash(); err == nil {
+			_, _ = s.passwordHasher.Verify(req.Password, hashed)
+		}
+		s.recordFailedLogin(ctx, s.unknownUserLockout, req.UserName, ip)
+		return nil, xerrors.Unauthenticated("username or password is not correctly").WithReason(reasonInvalidCredentials)
+	}
+
+	needsRehash, err := s.passwordHasher.Verify(req.Password, user.Password)
+	if err != nil {
+		if !errors.Is(err, crypto_utils.ErrPasswordMismatch) {
+			logger.FromContext(ctx).Error("unable to verify password", "user_id", user.ID, "err", err)
+		}
+		s.recordFailedLogin(ctx, s.userLockout, req.UserName, ip)
+		return nil, xerrors.Unauthenticated("username or password is not correctly").WithReason(reasonInvalidCredentials)
+	}
+
+	if needsRehash {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	mfaEnabled, err := s.mfaService.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// failures are kept until the code is verified, otherwise a known password would reset the lockout of guessing codes.
+	if mfaEnabled {
+		challengeID, err := crypto_utils.GenerateRandomToken(challengeIDSize)
+		if err != nil {
+			return nil, err
+		}
+
+		challenge := &entities.MFAChallenge{ID: challengeID, UserID: user.ID}
+		token, err := s.challengeGenerator.Generate(challenge, s.challengeTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		return &entities.LoginResult{
+			User:                  user,
+			MFAChallenge:          token,
+			MFAChallengeExpiredAt: challenge.ExpiredAt,
+		}, nil
+	}
+
+	s.resetLockout(ctx, user)
+
+	// a login starts a new rotation family.
+	tokens, err := s.issueTokens(ctx, s.pgClient, user, s.idGenerator.Int64())
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// LoginMFA is implementation to business logic for exchanging a challenge token of login and a code for tokens.
+// Wrong codes are failed logins of the user, so guessing codes is locked out like guessing passwords.
+func (s *authService) LoginMFA(ctx context.Context, challengeToken, code string) (*entities.User, *entities.TokenPair, error) {
+	user, tokens, err := s.loginMFA(ctx, challengeToken, code)
+	if err != nil {
+		s.countFailedLogin(err)
+		return nil, nil, err
+	}
+
+	s.loginAttempts.WithLabelValues("success").Inc()
+
+	return user, tokens, nil
+}
+
+func (s *authService) loginMFA(ctx context.Context, challengeToken, code string) (*entities.User, *entities.TokenPair, error) {
+	challenge, err := s.challengeGenerator.Verify(challengeToken)
+	if err != nil {
+		return nil, nil, xerrors.Unauthenticated("mfa challenge is not valid: %w", err).WithReason(reasonMFAChallengeInvalid)
+	}
+	// a challenge without id can not be revoked, so it could be exchanged more than once.
+	if challenge.ID == "" {
+		return nil, nil, xerrors.Unauthenticated("mfa challenge is not valid").WithReason(reasonMFAChallengeInvalid)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, challenge.UserID)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, nil, xerrors.Unauthenticated("mfa challenge is not valid").WithReason(reasonMFAChallengeInvalid)
+		}
+		return nil, nil, err
+	}
+
+	ip := xcontext.ExtractClientIPFromContext(ctx)
+	if err := s.checkLockout(ctx, user.UserName, ip); err != nil {
+		return nil, nil, err
 	}
+
+	// the challenge is revoked before the code is verified and tokens are issued in the same transaction,
+	// so a used challenge or a concurrent exchange of it is rejected without consuming a code,
+	// and a wrong code rolls the revocation back, so the challenge is retried until the user is locked out.
+	var tokens *entities.TokenPair
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		revoked, err := s.tokenRevocationRepo.RevokeToken(ctx, tx, &entities.RevokedToken{
+			TokenID:   challenge.ID,
+			UserID:    user.ID,
+			ExpiredAt: challenge.ExpiredAt,
+		})
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return xerrors.Unauthenticated("mfa challenge has been used").WithReason(reasonMFAChallengeInvalid)
+		}
+
+		if err := s.mfaService.Verify(ctx, user.ID, code); err != nil {
+			if xerrors.IsKind(err, xerrors.KindUnauthenticated) {
+				s.recordFailedLogin(ctx, s.userLockout, user.UserName, ip)
+			}
+			return err
+		}
+
+		// a login starts a new rotation family.
+		tokens, err = s.issueTokens(ctx, tx, &user.User, s.idGenerator.Int64())
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	s.resetLockout(ctx, &user.User)
+
+	return &user.User, tokens, nil
 }
-func (s *authService) Login(ctx context.Context, req *entities.User) (*entities.User, string, error) {
 
-	user, _ := s.userByUserNameCache.Get(ctx, req.UserName)
-	if user == nil {
-		var err error
-		user, err = s.userRepo.GetUserByUserName(ctx, s.pgClient, req.UserName)
+func (s *authService) countFailedLogin(err error) {
+	result := "failure"
+	if xerrors.IsKind(err, xerrors.KindTooManyRequests) {
+		result = "throttled"
+	}
+	s.loginAttempts.WithLabelValues(result).Inc()
+}
+
+// checkLockout returns an error of [xerrors.KindTooManyRequests] if userName or ip is locked out.
+// Unknown usernames are locked out like existing ones, so a lockout does not tell whether a username exists.
+func (s *authService) checkLockout(ctx context.Context, userName, ip string) error {
+	if err := s.userLockout.Check(ctx, userName); err != nil {
+		return err
+	}
+	if err := s.unknownUserLockout.Check(ctx, userName); err != nil {
+		return err
+	}
+	if ip != "" {
+		return s.ipLockout.Check(ctx, ip)
+	}
+
+	return nil
+}
+
+// recordFailedLogin records a failed login of userName in users and of ip,
+// failures of recording are only logged because the login is rejected anyway.
+func (s *authService) recordFailedLogin(ctx context.Context, users *lockout.Tracker, userName, ip string) {
+	if err := users.Fail(ctx, userName); err != nil {
+		logger.FromContext(ctx).Warn("unable to record failed login", "err", err)
+	}
+	if ip != "" {
+		if err := s.ipLockout.Fail(ctx, ip); err != nil {
+			logger.FromContext(ctx).Warn("unable to record failed login", "err", err)
+		}
+	}
+}
+
+// resetLockout forgets failed logins of user after a successful login.
+// Failures of the ip are kept, a client guessing many usernames may also know a password.
+func (s *authService) resetLockout(ctx context.Context, user *entities.User) {
+	if err := s.userLockout.Reset(ctx, user.UserName); err != nil {
+		logger.FromContext(ctx).Warn("unable to reset failed logins", "user_id", user.ID, "err", err)
+	}
+}
+
+// rehashPassword replaces an outdated hash of user by a hash of current algorithm and parameters.
+// A failure is only logged, the login has already been verified and the hash is replaced by the next login.
+func (s *authService) rehashPassword(ctx context.Context, user *entities.User, password string) {
+	hashed, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger.FromContext(ctx).Warn("unable to rehash password", "user_id", user.ID, "err", err)
+		return
+	}
+
+	// the password may be changed meanwhile, then the hash is not replaced.
+	if err := s.userRepo.ReplacePasswordByID(ctx, s.pgClient, user.ID, user.Password, hashed); err != nil && !xerrors.IsKind(err, xerrors.KindNotFound) {
+		logger.FromContext(ctx).Warn("unable to rehash password", "user_id", user.ID, "err", err)
+	}
+}
+
+// Refresh is implementation to business logic for rotating a refresh token to a new token pair.
+// A refresh token is single-use, reusing a rotated token revokes all tokens of its family
+// because either the user or an attacker is holding a stolen token.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*entities.TokenPair, error) {
+	var (
+		tokens *entities.TokenPair
+		reused bool
+	)
+
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		rt, err := s.refreshTokenRepo.GetByTokenHashForUpdate(ctx, tx, crypto_utils.HashToken(refreshToken))
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return nil, "", fmt.Errorf("username or password is not correctly")
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.Unauthenticated("refresh token is not valid").WithReason(reasonRefreshTokenInvalid)
 			}
-			return nil, "", err
+			return err
+		}
+
+		if rt.RevokedAt.Valid {
+			return xerrors.Unauthenticated("refresh token has been revoked").WithReason(reasonRefreshTokenRevoked)
 		}
+
+		if rt.UsedAt.Valid {
+			// the revocation must be committed, so the error is returned after the transaction.
+			reused = true
+			return s.refreshTokenRepo.RevokeByFamilyID(ctx, tx, rt.FamilyID)
+		}
+
+		if time.Now().After(rt.ExpiredAt) {
+			return xerrors.Unauthenticated("refresh token has been expired").WithReason(reasonRefreshTokenExpired)
+		}
+
+		if err := s.refreshTokenRepo.MarkUsedByID(ctx, tx, rt.ID); err != nil {
+			return err
+		}
+
+		// retrieve user again because the role may be changed since the last token was issued.
+		user, err := s.userRepo.GetUserByID(ctx, tx, rt.UserID)
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.Unauthenticated("refresh token is not valid").WithReason(reasonRefreshTokenInvalid)
+			}
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, tx, &user.User, rt.FamilyID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, xerrors.Unauthenticated("refresh token has been reused, all sessions of this login are revoked").WithReason(reasonRefreshTokenReused)
+	}
+
+	return tokens, nil
+}
+
+// Logout is implementation to business logic for revoking all refresh tokens of the login that owns refreshToken
+// and the access token of the user in context, so neither can be used after logout.
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	userInfo, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return xerrors.Unauthenticated("%w", err)
 	}
 
-	if err := crypto_utils.CheckPassword(req.Password, user.Password); err != nil {
-		return nil, "", err
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		rt, err := s.refreshTokenRepo.GetByTokenHashForUpdate(ctx, tx, crypto_utils.HashToken(refreshToken))
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.Unauthenticated("refresh token is not valid").WithReason(reasonRefreshTokenInvalid)
+			}
+			return err
+		}
+
+		// a user can not log out logins of other users by their refresh tokens.
+		if rt.UserID != userInfo.UserID {
+			return xerrors.Unauthenticated("refresh token is not valid").WithReason(reasonRefreshTokenInvalid)
+		}
+
+		return s.refreshTokenRepo.RevokeByFamilyID(ctx, tx, rt.FamilyID)
+	}); err != nil {
+		return err
+	}
+
+	return s.tokenRevocationService.RevokeToken(ctx, userInfo)
+}
+
+// Unlock is implementation to business logic for unlocking a username locked out by failed logins.
+// Failures of client ips are not forgotten, they expire by themselves.
+func (s *authService) Unlock(ctx context.Context, userName string) error {
+	if err := s.userLockout.Reset(ctx, userName); err != nil {
+		return err
 	}
 
-	tkn, err := s.tknGenerator.Generate(&xcontext.UserInfo{
-		UserID: user.ID,
-		Role:   string(user.Role),
-	}, 24*time.Hour)
+	return s.unknownUserLockout.Reset(ctx, userName)
+}
+
+// issueTokens returns a new short-lived access token and a refresh token of the family,
+// only the hash of refresh token is stored.
+func (s *authService) issueTokens(ctx context.Context, db database.Executor, user *entities.User, familyID int64) (*entities.TokenPair, error) {
+	info := &xcontext.UserInfo{
+		TokenID: s.idGenerator.String(),
+		UserID:  user.ID,
+		Role:    string(user.Role),
+	}
+	accessToken, err := s.tknGenerator.Generate(info, s.accessTokenTTL)
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+
+	refreshToken, err := crypto_utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Create(ctx, db, &entities.RefreshToken{
+		ID:        s.idGenerator.Int64(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: crypto_utils.HashToken(refreshToken),
+		ExpiredAt: time.Now().Add(s.refreshTokenTTL),
+		CreatedAt: database.NullTime(time.Now()),
+	}); err != nil {
+		return nil, err
 	}
 
-	return user, tkn, nil
+	return &entities.TokenPair{
+		AccessToken:          accessToken,
+		AccessTokenExpiredAt: info.ExpiredAt,
+		RefreshToken:         refreshToken,
+	}, nil
 }
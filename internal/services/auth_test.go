@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/pkg/cache"
+	"user-management/pkg/crypto_utils"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/id_utils"
+	"user-management/pkg/lockout"
+	"user-management/pkg/lru"
+	"user-management/pkg/token_utils"
+	"user-management/pkg/xerrors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams are cheap parameters, so tests do not spend time on hashing.
+var testArgon2idParams = crypto_utils.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newTestLockout(t *testing.T, maxFailures int) *lockout.Tracker {
+	tracker, err := lockout.NewTracker(
+		lockout.NewMemoryCounter(lru.NewLRU[string, int64](16, time.Hour)),
+		lru.NewLRU[string, time.Time](16, time.Hour),
+		"",
+		lockout.Policy{MaxFailures: maxFailures, BaseLockout: time.Minute, MaxLockout: time.Hour},
+	)
+	require.NoError(t, err)
+
+	return tracker
+}
+
+// newTestAuthService returns a service of users with password "secret", it locks out a username after 3 failures
+// and an ip after 5 failures. The repository keeps users, so changes of them are seen by tests.
+func newTestAuthService(t *testing.T, users ...*entities.User) (*authService, *fakeMFAService) {
+	hasher, err := crypto_utils.NewPasswordHasher(crypto_utils.AlgorithmArgon2id, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+	hashed, err := hasher.Hash("secret")
+	require.NoError(t, err)
+	for _, u := range users {
+		u.Password = hashed
+	}
+
+	tokenGenerator, err := token_utils.NewPasetoAuthenticator[*xcontext.UserInfo]("NUWe6IcMRNwLQU1qduIAj7Yntf5mRLnv")
+	require.NoError(t, err)
+	challengeGenerator, err := token_utils.NewPasetoAuthenticator[*entities.MFAChallenge]("ZdQ7cGV1pPBvG2m3xLwR9tYk0sHfJ8eA")
+	require.NoError(t, err)
+
+	mfa := newFakeMFAService()
+	s := NewAuthService(AuthServiceDeps{
+		PGClient:            newFakePostgresClient(t),
+		IDGenerator:         id_utils.NewSnowFlake(1),
+		PasswordHasher:      hasher,
+		TokenGenerator:      tokenGenerator,
+		AccessTokenTTL:      time.Minute,
+		RefreshTokenTTL:     time.Hour,
+		ChallengeGenerator:  challengeGenerator,
+		ChallengeTTL:        time.Minute,
+		MFAService:          mfa,
+		UserByUserNameCache: cache.NewLoader[string, *entities.User](lru.NewLRU[string, *cache.Entry[*entities.User]](16, time.Hour)),
+		UserLockout:         newTestLockout(t, 3),
+		UnknownUserLockout:  newTestLockout(t, 3),
+		IPLockout:           newTestLockout(t, 5),
+		Registerer:          prometheus.NewRegistry(),
+	}).(*authService)
+	s.userRepo = newFakeUserRepo(users...)
+	s.refreshTokenRepo = newFakeRefreshTokenRepo()
+	s.tokenRevocationRepo = newFakeTokenRevocationRepo()
+
+	return s, mfa
+}
+
+func TestRehashPasswordOnLogin(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1, UserName: "dat", Role: entities.UserRole}
+	s, _ := newTestAuthService(t, user)
+
+	// the user was created when passwords were hashed by bcrypt.
+	bcryptHasher, err := crypto_utils.NewPasswordHasher(crypto_utils.AlgorithmBcrypt, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+	user.Password, err = bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+
+	result, err := s.Login(ctx, &entities.User{UserName: "dat", Password: "secret"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+
+	assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"), user.Password)
+	needsRehash, err := s.passwordHasher.Verify("secret", user.Password)
+	require.NoError(t, err)
+	assert.False(t, needsRehash)
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestAuthService(t, &entities.User{ID: 1, UserName: "dat", Role: entities.UserRole})
+
+	// existing and unknown usernames are locked out alike.
+	for _, name := range []string{"dat", "ghost"} {
+		for i := 0; i < 3; i++ {
+			_, err := s.Login(ctx, &entities.User{UserName: name, Password: "wrong"})
+			assert.Equal(t, reasonInvalidCredentials, xerrors.ReasonOf(err))
+		}
+
+		_, err := s.Login(ctx, &entities.User{UserName: name, Password: "secret"})
+		assert.Equal(t, xerrors.KindTooManyRequests, xerrors.KindOf(err))
+		assert.Equal(t, time.Minute, xerrors.RetryAfterOf(err).Round(time.Minute))
+	}
+
+	// failures of unknown usernames are not tracked with failures of existing users.
+	assert.NoError(t, s.userLockout.Check(ctx, "ghost"))
+	assert.NoError(t, s.unknownUserLockout.Check(ctx, "dat"))
+
+	require.NoError(t, s.Unlock(ctx, "dat"))
+	result, err := s.Login(ctx, &entities.User{UserName: "dat", Password: "secret"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+}
+
+func TestLoginLockoutByIP(t *testing.T) {
+	ctx := xcontext.ImportClientIPToContext(context.Background(), "10.0.0.1")
+	s, _ := newTestAuthService(t, &entities.User{ID: 1, UserName: "dat", Role: entities.UserRole})
+
+	// guessing passwords of many usernames locks out the ip.
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		_, err := s.Login(ctx, &entities.User{UserName: name, Password: "wrong"})
+		assert.Equal(t, reasonInvalidCredentials, xerrors.ReasonOf(err))
+	}
+
+	_, err := s.Login(ctx, &entities.User{UserName: "dat", Password: "secret"})
+	assert.Equal(t, xerrors.KindTooManyRequests, xerrors.KindOf(err))
+
+	_, err = s.Login(context.Background(), &entities.User{UserName: "dat", Password: "secret"})
+	assert.NoError(t, err)
+}
+
+func TestLoginMFA(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1, UserName: "dat", Role: entities.UserRole}
+	s, mfa := newTestAuthService(t, user)
+	mfa.enable(user.ID, "111111", "222222", "333333")
+
+	login := func() string {
+		result, err := s.Login(ctx, &entities.User{UserName: "dat", Password: "secret"})
+		require.NoError(t, err)
+		require.Nil(t, result.Tokens)
+		require.NotEmpty(t, result.MFAChallenge)
+
+		return result.MFAChallenge
+	}
+
+	// a wrong code does not use the challenge.
+	challenge := login()
+	_, _, err := s.LoginMFA(ctx, challenge, "000000")
+	assert.Equal(t, reasonMFACodeInvalid, xerrors.ReasonOf(err))
+
+	_, tokens, err := s.LoginMFA(ctx, challenge, "111111")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	// a challenge is exchanged once, a replay does not consume the code.
+	_, _, err = s.LoginMFA(ctx, challenge, "222222")
+	assert.Equal(t, reasonMFAChallengeInvalid, xerrors.ReasonOf(err))
+	_, _, err = s.LoginMFA(ctx, login(), "222222")
+	require.NoError(t, err)
+
+	// an access token can not be exchanged as a challenge.
+	_, _, err = s.LoginMFA(ctx, tokens.AccessToken, "333333")
+	assert.Equal(t, reasonMFAChallengeInvalid, xerrors.ReasonOf(err))
+
+	// wrong codes are failed logins of the user.
+	challenge = login()
+	for i := 0; i < 3; i++ {
+		_, _, err = s.LoginMFA(ctx, challenge, "000000")
+		assert.Equal(t, reasonMFACodeInvalid, xerrors.ReasonOf(err))
+	}
+	_, _, err = s.LoginMFA(ctx, challenge, "333333")
+	assert.Equal(t, xerrors.KindTooManyRequests, xerrors.KindOf(err))
+}
+
+func TestLoginMFAConcurrentExchanges(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1, UserName: "dat", Role: entities.UserRole}
+	s, mfa := newTestAuthService(t, user)
+	codes := []string{"111111", "222222", "333333", "444444"}
+	mfa.enable(user.ID, codes...)
+
+	result, err := s.Login(ctx, &entities.User{UserName: "dat", Password: "secret"})
+	require.NoError(t, err)
+
+	// a challenge only issues tokens once, even if exchanges by valid codes race,
+	// and the exchanges rejected do not consume their codes.
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		issued int
+	)
+	for _, code := range codes {
+		wg.Add(1)
+		go func(code string) {
+			defer wg.Done()
+			if _, _, err := s.LoginMFA(ctx, result.MFAChallenge, code); err == nil {
+				mu.Lock()
+				issued++
+				mu.Unlock()
+			} else {
+				assert.Equal(t, reasonMFAChallengeInvalid, xerrors.ReasonOf(err))
+			}
+		}(code)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, issued)
+	assert.Len(t, mfa.codes[user.ID], len(codes)-1)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"user-management/internal/entities"
+	"user-management/pkg/cache"
+	"user-management/pkg/events"
+	"user-management/pkg/logger"
+)
+
+// cacheCoherence is the only place that maps changes of repositories to cached keys,
+// so services never remove cached values by themselves.
+type cacheCoherence struct {
+	userCache           cache.Cache[int64, *entities.UserWithAccounts]
+	userByUserNameCache cache.Cache[string, *entities.User]
+	accountCache        cache.Cache[int64, *entities.Account]
+}
+
+// NewCacheCoherenceHandler returns a handler of changes which removes every cached value affected by a change,
+// so reads after writes are fresh.
+func NewCacheCoherenceHandler(
+	userCache cache.Cache[int64, *entities.UserWithAccounts],
+	userByUserNameCache cache.Cache[string, *entities.User],
+	accountCache cache.Cache[int64, *entities.Account],
+) events.Handler[entities.Change] {
+	c := &cacheCoherence{
+		userCache:           userCache,
+		userByUserNameCache: userByUserNameCache,
+		accountCache:        accountCache,
+	}
+
+	return c.handle
+}
+
+// handle removes keys affected by change, a missing key is not cached and is ignored.
+func (c *cacheCoherence) handle(ctx context.Context, change entities.Change) {
+	switch change := change.(type) {
+	case entities.UserChanged:
+		logRemoveError(ctx, "user", change.ID, c.userCache.Remove(ctx, change.ID))
+		// a created user may be cached as not found by its user name.
+		logRemoveError(ctx, "user_by_user_name", change.UserName, c.userByUserNameCache.Remove(ctx, change.UserName))
+	case entities.AccountChanged:
+		logRemoveError(ctx, "account", change.ID, c.accountCache.Remove(ctx, change.ID))
+		// the user caches the ids of its accounts.
+		logRemoveError(ctx, "user", change.UserID, c.userCache.Remove(ctx, change.UserID))
+	}
+}
+
+// logRemoveError logs a failed removal by the logger of request, the stale value is served until it expires.
+func logRemoveError(ctx context.Context, cacheName string, key any, err error) {
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		logger.FromContext(ctx).Warn("unable to remove changed value from cache", "cache", cacheName, "key", key, "err", err)
+	}
+}
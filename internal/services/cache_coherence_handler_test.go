@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/pkg/cache"
+	"user-management/pkg/lru"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheCoherenceHandler(t *testing.T) {
+	ctx := context.Background()
+	userCache := lru.NewLRU[int64, *entities.UserWithAccounts](16, time.Hour)
+	userByUserNameCache := lru.NewLRU[string, *entities.User](16, time.Hour)
+	accountCache := lru.NewLRU[int64, *entities.Account](16, time.Hour)
+	handle := NewCacheCoherenceHandler(userCache, userByUserNameCache, accountCache)
+
+	for _, id := range []int64{1, 2} {
+		require.NoError(t, userCache.Add(ctx, id, &entities.UserWithAccounts{}))
+		require.NoError(t, accountCache.Add(ctx, id*10, &entities.Account{ID: id * 10, UserID: id}))
+	}
+	require.NoError(t, userByUserNameCache.Add(ctx, "dat", &entities.User{ID: 1}))
+
+	// a changed user is removed by id and by user name.
+	handle(ctx, entities.UserChanged{ID: 1, UserName: "dat"})
+	_, err := userCache.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	_, err = userByUserNameCache.Get(ctx, "dat")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	// a changed account is removed with its owner, which caches the ids of its accounts.
+	handle(ctx, entities.AccountChanged{ID: 20, UserID: 2})
+	_, err = accountCache.Get(ctx, 20)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	_, err = userCache.Get(ctx, 2)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	// values not affected by changes are kept, and changes of keys not cached are ignored.
+	_, err = accountCache.Get(ctx, 10)
+	assert.NoError(t, err)
+	handle(ctx, entities.AccountChanged{ID: 30, UserID: 3})
+}
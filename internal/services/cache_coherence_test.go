@@ -0,0 +1,261 @@
+//go:build integration
+
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/internal/services"
+	"user-management/migrations"
+	"user-management/pkg/cache"
+	"user-management/pkg/crypto_utils"
+	"user-management/pkg/database"
+	"user-management/pkg/events"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/id_utils"
+	"user-management/pkg/lru"
+	"user-management/pkg/migrate"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cacheCoherenceSuite asserts that reads after writes are fresh while every read goes through the caches.
+// It runs against the postgres of TEST_POSTGRES_ADDRESS which is migrated up first:
+//
+//	TEST_POSTGRES_ADDRESS="host=localhost port=5432 user=... password=... dbname=... sslmode=disable" go test -tags integration ./internal/services/
+type cacheCoherenceSuite struct {
+	suite.Suite
+
+	ctx            context.Context
+	pgClient       *postgres_client.PostgresClient
+	idGenerator    id_utils.IDGenerator
+	passwordHasher crypto_utils.PasswordHasher
+
+	userByUserNameCache cache.LoadingCache[string, *entities.User]
+	accountCache        cache.LoadingCache[int64, *entities.Account]
+
+	userService     services.UserService
+	accountService  services.AccountService
+	transferService services.TransferService
+}
+
+func TestCacheCoherence(t *testing.T) {
+	address := os.Getenv("TEST_POSTGRES_ADDRESS")
+	if address == "" {
+		t.Skip("TEST_POSTGRES_ADDRESS is not set")
+	}
+
+	suite.Run(t, &cacheCoherenceSuite{pgClient: postgres_client.NewPostgresClient(address)})
+}
+
+func (s *cacheCoherenceSuite) SetupSuite() {
+	ctx := context.Background()
+	s.Require().NoError(s.pgClient.Connect(ctx))
+
+	all, err := migrate.Load(migrations.FS)
+	s.Require().NoError(err)
+	_, err = migrate.New(s.pgClient.DB, all).Up(ctx, 0)
+	s.Require().NoError(err)
+
+	// entries are never stale, so every read after a write is served by cache unless the write removed it.
+	userCache := cache.NewLoader(lru.NewLRU[int64, *cache.Entry[*entities.UserWithAccounts]](128, time.Hour), cache.WithNotFoundTTL(time.Hour))
+	s.accountCache = cache.NewLoader(lru.NewLRU[int64, *cache.Entry[*entities.Account]](128, time.Hour), cache.WithNotFoundTTL(time.Hour))
+	s.userByUserNameCache = cache.NewLoader(lru.NewLRU[string, *cache.Entry[*entities.User]](128, time.Hour), cache.WithNotFoundTTL(time.Hour))
+
+	changes := events.NewBus[entities.Change]()
+	changes.Subscribe(services.NewCacheCoherenceHandler(userCache, s.userByUserNameCache, s.accountCache))
+
+	s.idGenerator = id_utils.NewSnowFlake(1)
+	s.passwordHasher, err = crypto_utils.NewPasswordHasher(crypto_utils.AlgorithmArgon2id, crypto_utils.DefaultArgon2idParams, bcrypt.MinCost)
+	s.Require().NoError(err)
+	tokenRevocationService := services.NewTokenRevocationService(s.pgClient, lru.NewLRU[string, time.Time](128, time.Hour))
+	s.userService = services.NewUserService(s.pgClient, s.idGenerator, s.passwordHasher, userCache, s.userByUserNameCache, changes, tokenRevocationService)
+	s.accountService = services.NewAccountService(s.pgClient, s.accountCache, changes)
+	s.transferService = services.NewTransferService(s.pgClient, s.idGenerator, changes)
+
+	s.ctx = xcontext.ImportUserInfoToContext(ctx, &xcontext.UserInfo{
+		UserID: s.idGenerator.Int64(),
+		Role:   string(entities.SuperAdminRole),
+	})
+}
+
+func (s *cacheCoherenceSuite) TearDownSuite() {
+	s.pgClient.Close(context.Background())
+}
+
+// createUser creates a user and caches it.
+func (s *cacheCoherenceSuite) createUser() *entities.UserWithAccounts {
+	id, err := s.userService.CreateUser(s.ctx, &entities.User{
+		Name:     database.NullString("coherence"),
+		UserName: fmt.Sprintf("coherence_%d", s.idGenerator.Int64()),
+		Password: "secret",
+		Role:     entities.UserRole,
+	})
+	s.Require().NoError(err)
+
+	user, err := s.userService.GetUserByID(s.ctx, id)
+	s.Require().NoError(err)
+
+	return user
+}
+
+// createAccount creates an account of user with balance and caches it.
+func (s *cacheCoherenceSuite) createAccount(userID int64, balance int64) *entities.Account {
+	id, err := s.userService.CreateAccount(s.ctx, &entities.Account{UserID: userID})
+	s.Require().NoError(err)
+
+	if balance > 0 {
+		// there is no deposit yet, the balance is set before the account is cached.
+		_, err := s.pgClient.ExecContext(s.ctx, "UPDATE accounts SET balance = $2 WHERE id = $1", id, balance)
+		s.Require().NoError(err)
+	}
+
+	account, err := s.accountService.GetAccountByID(s.ctx, id)
+	s.Require().NoError(err)
+
+	return account
+}
+
+func (s *cacheCoherenceSuite) getUserByUserName(userName string) (*entities.User, error) {
+	userRepo := repositories.NewUserRepository(nil)
+
+	return s.userByUserNameCache.GetOrLoad(s.ctx, userName, func(ctx context.Context) (*entities.User, error) {
+		return userRepo.GetUserByUserName(ctx, s.pgClient, userName)
+	})
+}
+
+// getAccount reads account through cache without authorization, so accounts of deleted users can be read.
+func (s *cacheCoherenceSuite) getAccount(id int64) (*entities.Account, error) {
+	accountRepo := repositories.NewAccountRepository(nil)
+
+	return s.accountCache.GetOrLoad(s.ctx, id, func(ctx context.Context) (*entities.Account, error) {
+		return accountRepo.GetAccountByID(ctx, s.pgClient, id)
+	})
+}
+
+func (s *cacheCoherenceSuite) TestCreateUser() {
+	userName := fmt.Sprintf("coherence_%d", s.idGenerator.Int64())
+	_, err := s.getUserByUserName(userName)
+	s.Require().True(xerrors.IsKind(err, xerrors.KindNotFound))
+
+	id, err := s.userService.CreateUser(s.ctx, &entities.User{UserName: userName, Password: "secret", Role: entities.UserRole})
+	s.Require().NoError(err)
+
+	user, err := s.getUserByUserName(userName)
+	s.Require().NoError(err)
+	s.Equal(id, user.ID)
+}
+
+func (s *cacheCoherenceSuite) TestUpdateUser() {
+	user := s.createUser()
+
+	s.Require().NoError(s.userService.Update(s.ctx, &entities.User{ID: user.ID, Name: database.NullString("renamed")}))
+
+	got, err := s.userService.GetUserByID(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Equal("renamed", got.Name.String)
+}
+
+func (s *cacheCoherenceSuite) TestCreateAndDeleteAccount() {
+	user := s.createUser()
+	s.Empty(user.AccountIDs)
+
+	account := s.createAccount(user.ID, 0)
+	got, err := s.userService.GetUserByID(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Equal([]int64{account.ID}, []int64(got.AccountIDs))
+
+	s.Require().NoError(s.accountService.DeleteByID(s.ctx, account.ID))
+
+	_, err = s.accountService.GetAccountByID(s.ctx, account.ID)
+	s.True(xerrors.IsKind(err, xerrors.KindNotFound))
+	got, err = s.userService.GetUserByID(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Empty(got.AccountIDs)
+}
+
+func (s *cacheCoherenceSuite) TestUpdateAccount() {
+	user := s.createUser()
+	account := s.createAccount(user.ID, 0)
+
+	s.Require().NoError(s.accountService.Update(s.ctx, &entities.Account{
+		ID:     account.ID,
+		Name:   database.NullString("savings"),
+		Status: entities.FrozenStatus,
+	}))
+
+	got, err := s.accountService.GetAccountByID(s.ctx, account.ID)
+	s.Require().NoError(err)
+	s.Equal("savings", got.Name.String)
+	s.Equal(entities.FrozenStatus, got.Status)
+}
+
+func (s *cacheCoherenceSuite) TestTransfer() {
+	user := s.createUser()
+	from := s.createAccount(user.ID, 1000)
+	to := s.createAccount(user.ID, 0)
+
+	_, err := s.transferService.Transfer(s.ctx, &entities.Transfer{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 300})
+	s.Require().NoError(err)
+
+	got, err := s.accountService.GetAccountByID(s.ctx, from.ID)
+	s.Require().NoError(err)
+	s.Equal(int64(700), got.Balance.Int64)
+
+	got, err = s.accountService.GetAccountByID(s.ctx, to.ID)
+	s.Require().NoError(err)
+	s.Equal(int64(300), got.Balance.Int64)
+}
+
+func (s *cacheCoherenceSuite) TestDeleteUser() {
+	user := s.createUser()
+	account := s.createAccount(user.ID, 0)
+	_, err := s.getUserByUserName(user.UserName)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.userService.DeleteByID(s.ctx, user.ID))
+
+	_, err = s.userService.GetUserByID(s.ctx, user.ID)
+	s.True(xerrors.IsKind(err, xerrors.KindNotFound))
+	_, err = s.getUserByUserName(user.UserName)
+	s.True(xerrors.IsKind(err, xerrors.KindNotFound))
+
+	got, err := s.getAccount(account.ID)
+	s.Require().NoError(err)
+	s.Equal(entities.FrozenStatus, got.Status)
+}
+
+func (s *cacheCoherenceSuite) TestPurgeUser() {
+	user := s.createUser()
+	account := s.createAccount(user.ID, 0)
+
+	s.Require().NoError(s.userService.PurgeByID(s.ctx, user.ID))
+
+	_, err := s.userService.GetUserByID(s.ctx, user.ID)
+	s.True(xerrors.IsKind(err, xerrors.KindNotFound))
+	_, err = s.getAccount(account.ID)
+	s.True(xerrors.IsKind(err, xerrors.KindNotFound))
+}
+
+func (s *cacheCoherenceSuite) TestRollback() {
+	user := s.createUser()
+	from := s.createAccount(user.ID, 0)
+	to := s.createAccount(user.ID, 0)
+
+	// the transfer is rolled back by insufficient funds, so no change is published and the account is still cached.
+	_, err := s.transferService.Transfer(s.ctx, &entities.Transfer{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1})
+	s.Require().True(xerrors.IsKind(err, xerrors.KindConflict))
+
+	cached, err := s.accountCache.Get(s.ctx, from.ID)
+	s.Require().NoError(err)
+	s.Same(from, cached)
+}
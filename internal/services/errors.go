@@ -0,0 +1,33 @@
+package services
+
+// reasons of domain errors, they are returned to clients and must be kept stable.
+const (
+	reasonUserNotFound          = "USER_NOT_FOUND"
+	reasonUserNameAlreadyExists = "USERNAME_ALREADY_EXISTS"
+	reasonInvalidCredentials    = "INVALID_CREDENTIALS"
+	reasonRoleChangeDenied      = "ROLE_CHANGE_DENIED"
+	reasonNotResourceOwner      = "NOT_RESOURCE_OWNER"
+	reasonLastSuperAdmin        = "LAST_SUPER_ADMIN"
+	reasonUserHasTransfers      = "USER_HAS_TRANSFERS"
+
+	reasonRefreshTokenInvalid = "REFRESH_TOKEN_INVALID"
+	reasonRefreshTokenExpired = "REFRESH_TOKEN_EXPIRED"
+	reasonRefreshTokenRevoked = "REFRESH_TOKEN_REVOKED"
+	reasonRefreshTokenReused  = "REFRESH_TOKEN_REUSED"
+
+	reasonMFANotEnrolled      = "MFA_NOT_ENROLLED"
+	reasonMFAAlreadyEnabled   = "MFA_ALREADY_ENABLED"
+	reasonMFACodeInvalid      = "MFA_CODE_INVALID"
+	reasonMFAChallengeInvalid = "MFA_CHALLENGE_INVALID"
+
+	reasonAccountNotFound       = "ACCOUNT_NOT_FOUND"
+	reasonAccountFrozen         = "ACCOUNT_FROZEN"
+	reasonAccountClosed         = "ACCOUNT_CLOSED"
+	reasonAccountBalanceNotZero = "ACCOUNT_BALANCE_NOT_ZERO"
+	reasonAccountHasTransfers   = "ACCOUNT_HAS_TRANSFERS"
+	reasonAccountStatusDenied   = "ACCOUNT_STATUS_DENIED"
+	reasonCurrencyNotSupported  = "CURRENCY_NOT_SUPPORTED"
+	reasonCurrencyMismatch      = "CURRENCY_MISMATCH"
+	reasonExchangeRateNotFound  = "EXCHANGE_RATE_NOT_FOUND"
+	reasonInsufficientFunds     = "INSUFFICIENT_FUNDS"
+)
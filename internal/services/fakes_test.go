@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"user-management/internal/entities"
+	"user-management/pkg/database"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+// txDriver is a sql driver of transactions doing nothing but running one at a time, so services using
+// transactions run with fake repositories which ignore their executor. Fake repositories undo their writes
+// in a transaction by [onRollback].
+type txDriver struct{}
+
+var (
+	// txMu is held by the running transaction.
+	txMu sync.Mutex
+	// rollbacks undo writes of the running transaction.
+	rollbacks []func()
+)
+
+func (txDriver) Open(string) (driver.Conn, error) { return txConn{}, nil }
+
+type txConn struct{}
+
+func (txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (txConn) Close() error                        { return nil }
+
+func (c txConn) Begin() (driver.Tx, error) {
+	txMu.Lock()
+	rollbacks = nil
+
+	return c, nil
+}
+
+func (c txConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c.Begin() }
+
+func (txConn) Commit() error {
+	rollbacks = nil
+	txMu.Unlock()
+
+	return nil
+}
+
+func (txConn) Rollback() error {
+	for i := len(rollbacks) - 1; i >= 0; i-- {
+		rollbacks[i]()
+	}
+	rollbacks = nil
+	txMu.Unlock()
+
+	return nil
+}
+
+func init() {
+	sql.Register("fake_tx", txDriver{})
+}
+
+// newFakePostgresClient returns a client of [txDriver].
+func newFakePostgresClient(t *testing.T) *postgres_client.PostgresClient {
+	db, err := sql.Open("fake_tx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &postgres_client.PostgresClient{DB: db}
+}
+
+// onRollback registers undo of a write by db, if db is the executor of a transaction.
+func onRollback(db database.Executor, undo func()) {
+	if _, ok := db.(*postgres_client.PostgresClient); ok || db == nil {
+		return
+	}
+	rollbacks = append(rollbacks, undo)
+}
+
+// fakeUserRepo is an in memory user repository, so business logic is tested without postgres.
+type fakeUserRepo struct {
+	mu    sync.Mutex
+	users map[int64]*entities.User
+}
+
+func newFakeUserRepo(users ...*entities.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[int64]*entities.User)}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+
+	return r
+}
+
+func (r *fakeUserRepo) Create(_ context.Context, _ database.Executor, data *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := *data
+	r.users[u.ID] = &u
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateByID(_ context.Context, _ database.Executor, id int64, data *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid {
+		return xerrors.NotFound("no row affected")
+	}
+	if data.Name.Valid {
+		u.Name = data.Name
+	}
+	if data.Password != "" {
+		u.Password = data.Password
+	}
+	if data.Role != "" {
+		u.Role = data.Role
+	}
+
+	return nil
+}
+
+func (r *fakeUserRepo) GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error) {
+	u, err := r.GetUserByIDWithDeleted(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.DeletedAt.Valid {
+		return nil, xerrors.NotFound("sql: no rows in result set")
+	}
+
+	return u, nil
+}
+
+func (r *fakeUserRepo) GetUserByIDWithDeleted(_ context.Context, _ database.Executor, id int64) (*entities.UserWithAccounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, xerrors.NotFound("sql: no rows in result set")
+	}
+
+	return &entities.UserWithAccounts{User: *u}, nil
+}
+
+func (r *fakeUserRepo) GetUserByUserName(_ context.Context, _ database.Executor, userName string) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.UserName == userName && !u.DeletedAt.Valid {
+			result := *u
+			return &result, nil
+		}
+	}
+
+	return nil, xerrors.NotFound("sql: no rows in result set")
+}
+
+func (r *fakeUserRepo) ReplacePasswordByID(_ context.Context, _ database.Executor, id int64, oldPassword, newPassword string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid || u.Password != oldPassword {
+		return xerrors.NotFound("no row affected")
+	}
+	u.Password = newPassword
+
+	return nil
+}
+
+func (r *fakeUserRepo) DeleteByID(_ context.Context, _ database.Executor, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid {
+		return xerrors.NotFound("no row affected")
+	}
+	u.DeletedAt.Valid = true
+
+	return nil
+}
+
+func (r *fakeUserRepo) PurgeByID(_ context.Context, _ database.Executor, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return xerrors.NotFound("no row affected")
+	}
+	delete(r.users, id)
+
+	return nil
+}
+
+func (r *fakeUserRepo) CountByRole(_ context.Context, _ database.Executor, role entities.User_Role) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, u := range r.users {
+		if u.Role == role && !u.DeletedAt.Valid {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// fakeAccountRepo is an in memory account repository.
+type fakeAccountRepo struct {
+	mu       sync.Mutex
+	accounts map[int64]*entities.Account
+}
+
+func newFakeAccountRepo(accounts ...*entities.Account) *fakeAccountRepo {
+	r := &fakeAccountRepo{accounts: make(map[int64]*entities.Account)}
+	for _, a := range accounts {
+		r.accounts[a.ID] = a
+	}
+
+	return r
+}
+
+func (r *fakeAccountRepo) Create(_ context.Context, _ database.Executor, data *entities.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a := *data
+	r.accounts[a.ID] = &a
+	return nil
+}
+
+func (r *fakeAccountRepo) ListAccountByUserID(_ context.Context, _ database.Executor, userID int64) ([]*entities.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*entities.Account
+	for _, a := range r.accounts {
+		if a.UserID == userID {
+			account := *a
+			result = append(result, &account)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *fakeAccountRepo) FreezeByUserID(_ context.Context, _ database.Executor, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range r.accounts {
+		if a.UserID == userID {
+			a.Status = entities.FrozenStatus
+		}
+	}
+
+	return nil
+}
+
+// fakeRefreshTokenRepo is an in memory refresh token repository.
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*entities.RefreshToken
+}
+
+func newFakeRefreshTokenRepo() *fakeRefreshTokenRepo {
+	return &fakeRefreshTokenRepo{tokens: make(map[string]*entities.RefreshToken)}
+}
+
+func (r *fakeRefreshTokenRepo) Create(_ context.Context, _ database.Executor, data *entities.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := *data
+	r.tokens[t.TokenHash] = &t
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetByTokenHashForUpdate(_ context.Context, _ database.Executor, tokenHash string) (*entities.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, xerrors.NotFound("sql: no rows in result set")
+	}
+	result := *t
+
+	return &result, nil
+}
+
+func (r *fakeRefreshTokenRepo) MarkUsedByID(_ context.Context, _ database.Executor, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.ID == id {
+			t.UsedAt.Valid = true
+			return nil
+		}
+	}
+
+	return xerrors.NotFound("no row affected")
+}
+
+func (r *fakeRefreshTokenRepo) RevokeByFamilyID(_ context.Context, _ database.Executor, familyID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID {
+			t.RevokedAt.Valid = true
+		}
+	}
+
+	return nil
+}
+
+// fakeTokenRevocationRepo is an in memory token revocation repository.
+type fakeTokenRevocationRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*entities.RevokedToken
+}
+
+func newFakeTokenRevocationRepo() *fakeTokenRevocationRepo {
+	return &fakeTokenRevocationRepo{tokens: make(map[string]*entities.RevokedToken)}
+}
+
+func (r *fakeTokenRevocationRepo) RevokeToken(_ context.Context, db database.Executor, data *entities.RevokedToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[data.TokenID]; ok {
+		return false, nil
+	}
+	t := *data
+	r.tokens[t.TokenID] = &t
+	onRollback(db, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		delete(r.tokens, t.TokenID)
+	})
+
+	return true, nil
+}
+
+// fakeMFAService accepts the codes of a user once, users without codes have not enabled mfa.
+type fakeMFAService struct {
+	MFAService
+
+	mu    sync.Mutex
+	codes map[int64]map[string]bool
+}
+
+func newFakeMFAService() *fakeMFAService {
+	return &fakeMFAService{codes: make(map[int64]map[string]bool)}
+}
+
+// enable enables mfa of user with codes which are accepted once.
+func (s *fakeMFAService) enable(userID int64, codes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[userID] = make(map[string]bool)
+	for _, code := range codes {
+		s.codes[userID][code] = true
+	}
+}
+
+func (s *fakeMFAService) Enabled(_ context.Context, userID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.codes[userID]
+	return ok, nil
+}
+
+func (s *fakeMFAService) Verify(_ context.Context, userID int64, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.codes[userID][code] {
+		return xerrors.Unauthenticated("mfa code is not valid").WithReason(reasonMFACodeInvalid)
+	}
+	delete(s.codes[userID], code)
+
+	return nil
+}
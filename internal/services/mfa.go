@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/pkg/crypto_utils"
+	"user-management/pkg/database"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/id_utils"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes issued by an activation.
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the number of random bytes of a recovery code, they are encoded to 8 base32 characters.
+	recoveryCodeSize = 5
+	// totpSkew is the number of steps accepted before and after now, so clocks of authenticators may drift.
+	totpSkew = 1
+)
+
+// MFAService is a TOTP service exporter to used for other layers.
+type MFAService interface {
+	// Enroll creates a new not enabled enrollment of the user in context, replacing a not enabled one.
+	Enroll(ctx context.Context) (*entities.MFAEnrollment, error)
+	// Activate enables the enrollment of the user in context by a code of it and returns new recovery codes.
+	Activate(ctx context.Context, code string) ([]string, error)
+	// Reset removes the enrollment of a user, so the user logs in by password only and may enroll again.
+	Reset(ctx context.Context, userID int64) error
+	// Enabled reports whether the user has enabled MFA.
+	Enabled(ctx context.Context, userID int64) (bool, error)
+	// Verify verifies a TOTP code or a recovery code of the user, every code is accepted only once.
+	Verify(ctx context.Context, userID int64, code string) error
+}
+
+// mfaService is a representation of service that implements business logic for mfa domain.
+type mfaService struct {
+	pgClient    *postgres_client.PostgresClient
+	idGenerator id_utils.IDGenerator
+	// sealer encrypts totp secrets, they must be read back to verify codes.
+	sealer *crypto_utils.Sealer
+	// issuer is shown by authenticator apps beside the user name.
+	issuer string
+	policy *ownershipPolicy
+
+	userRepo interface {
+		GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error)
+	}
+	mfaRepo interface {
+		UpsertPending(ctx context.Context, db database.Executor, data *entities.UserMFA) error
+		GetByUserID(ctx context.Context, db database.Executor, userID int64) (*entities.UserMFA, error)
+		EnableByUserID(ctx context.Context, db database.Executor, userID int64) error
+		UseStepByUserID(ctx context.Context, db database.Executor, userID int64, step int64) error
+		DeleteByUserID(ctx context.Context, db database.Executor, userID int64) error
+		ReplaceRecoveryCodes(ctx context.Context, db database.Executor, userID int64, codes []*entities.MFARecoveryCode) error
+		UseRecoveryCode(ctx context.Context, db database.Executor, userID int64, codeHash string) error
+	}
+}
+
+func NewMFAService(
+	pgClient *postgres_client.PostgresClient,
+	idGenerator id_utils.IDGenerator,
+	sealer *crypto_utils.Sealer,
+	issuer string,
+) MFAService {
+	return &mfaService{
+		pgClient:    pgClient,
+		idGenerator: idGenerator,
+		sealer:      sealer,
+		issuer:      issuer,
+		policy:      newOwnershipPolicy(),
+
+		// for repositories
+		// users are only read, so there is no change to publish.
+		userRepo: repositories.NewUserRepository(nil),
+		mfaRepo:  repositories.NewMFARepository(),
+	}
+}
+
+// Enroll is implementation to business logic for enrolling TOTP of the user in context.
+func (s *mfaService) Enroll(ctx context.Context) (*entities.MFAEnrollment, error) {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, userCtx.UserID)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+		}
+		return nil, err
+	}
+
+	secret, err := crypto_utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.sealer.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.UpsertPending(ctx, s.pgClient, &entities.UserMFA{
+		UserID:    user.ID,
+		Secret:    sealed,
+		CreatedAt: database.NullTime(time.Now()),
+	}); err != nil {
+		if xerrors.IsKind(err, xerrors.KindConflict) {
+			return nil, xerrors.Conflict("mfa has been enabled, it must be reset before enrolling again").WithReason(reasonMFAAlreadyEnabled)
+		}
+		return nil, err
+	}
+
+	return &entities.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: crypto_utils.TOTPProvisioningURI(s.issuer, user.UserName, secret),
+	}, nil
+}
+
+// Activate is implementation to business logic for enabling the enrollment of the user in context.
+// Recovery codes are only returned here, only their hashes are stored.
+func (s *mfaService) Activate(ctx context.Context, code string) ([]string, error) {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		mfa, err := s.mfaRepo.GetByUserID(ctx, tx, userCtx.UserID)
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.NotFound("mfa has not been enrolled").WithReason(reasonMFANotEnrolled)
+			}
+			return err
+		}
+
+		if mfa.EnabledAt.Valid {
+			return xerrors.Conflict("mfa has been enabled").WithReason(reasonMFAAlreadyEnabled)
+		}
+
+		if err := s.verifyTOTP(ctx, tx, mfa, code); err != nil {
+			return err
+		}
+
+		if err := s.mfaRepo.EnableByUserID(ctx, tx, mfa.UserID); err != nil {
+			return err
+		}
+
+		var recoveryCodes []*entities.MFARecoveryCode
+		codes, recoveryCodes, err = s.generateRecoveryCodes(mfa.UserID)
+		if err != nil {
+			return err
+		}
+
+		return s.mfaRepo.ReplaceRecoveryCodes(ctx, tx, mfa.UserID, recoveryCodes)
+	}); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Reset is implementation to business logic for removing the enrollment of a user by an admin.
+func (s *mfaService) Reset(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, userID)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+		}
+		return err
+	}
+
+	if err := s.policy.authorizeUser(ctx, &user.User); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteByUserID(ctx, s.pgClient, userID); err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return xerrors.NotFound("mfa has not been enrolled").WithReason(reasonMFANotEnrolled)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Enabled is implementation to business logic for checking whether a user has enabled MFA.
+func (s *mfaService) Enabled(ctx context.Context, userID int64) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, s.pgClient, userID)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return mfa.EnabledAt.Valid, nil
+}
+
+// Verify is implementation to business logic for verifying a TOTP code or a recovery code of an enabled enrollment.
+func (s *mfaService) Verify(ctx context.Context, userID int64, code string) error {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, s.pgClient, userID)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return errMFACodeInvalid()
+		}
+		return err
+	}
+
+	if !mfa.EnabledAt.Valid {
+		return errMFACodeInvalid()
+	}
+
+	if isTOTPCode(code) {
+		return s.verifyTOTP(ctx, s.pgClient, mfa, code)
+	}
+
+	if err := s.mfaRepo.UseRecoveryCode(ctx, s.pgClient, userID, crypto_utils.HashToken(normalizeRecoveryCode(code))); err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return errMFACodeInvalid()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// verifyTOTP verifies code by the secret of mfa and marks its step as used, so a code can not be replayed.
+func (s *mfaService) verifyTOTP(ctx context.Context, db database.Executor, mfa *entities.UserMFA, code string) error {
+	secret, err := s.sealer.Open(mfa.Secret)
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := crypto_utils.ValidateTOTP(secret, code, time.Now(), totpSkew)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errMFACodeInvalid()
+	}
+
+	// the step is only used if it is later than the last used one, a concurrent replay is rejected too.
+	if err := s.mfaRepo.UseStepByUserID(ctx, db, mfa.UserID, step); err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return errMFACodeInvalid()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes returns new recovery codes formatted like "ABCD-EFGH" and their entities to be stored.
+func (s *mfaService) generateRecoveryCodes(userID int64) ([]string, []*entities.MFARecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]*entities.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := base32.StdEncoding.EncodeToString(b)
+
+		codes = append(codes, code[:4]+"-"+code[4:])
+		recoveryCodes = append(recoveryCodes, &entities.MFARecoveryCode{
+			ID:        s.idGenerator.Int64(),
+			UserID:    userID,
+			CodeHash:  crypto_utils.HashToken(code),
+			CreatedAt: database.NullTime(time.Now()),
+		})
+	}
+
+	return codes, recoveryCodes, nil
+}
+
+// isTOTPCode reports whether code is formatted as a TOTP code, otherwise it is taken as a recovery code.
+func isTOTPCode(code string) bool {
+	if len(code) != crypto_utils.TOTPDigits {
+		return false
+	}
+
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeRecoveryCode removes separators and case of a recovery code typed by a user.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func errMFACodeInvalid() error {
+	return xerrors.Unauthenticated("mfa code is not valid").WithReason(reasonMFACodeInvalid)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/pkg/database"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/xerrors"
+)
+
+// ownershipPolicy decides whether the user in context may act on a resource by its owner.
+// A user may act on their own user and accounts, an admin may act on everyone except super admins
+// and a super admin may act on everyone.
+type ownershipPolicy struct {
+	userRepo interface {
+		GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error)
+	}
+}
+
+func newOwnershipPolicy() *ownershipPolicy {
+	return &ownershipPolicy{
+		// users are only read, so there is no change to publish.
+		userRepo: repositories.NewUserRepository(nil),
+	}
+}
+
+// authorizeUser returns a permission denied error if the user in context may not act on the given user.
+func (p *ownershipPolicy) authorizeUser(ctx context.Context, user *entities.User) error {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if userCtx.UserID == user.ID || canManage(entities.User_Role(userCtx.Role), user.Role) {
+		return nil
+	}
+
+	return xerrors.PermissionDenied("not allowed to access user %d", user.ID).WithReason(reasonNotResourceOwner)
+}
+
+// authorizeAccount returns a permission denied error if the user in context may not act on the given account.
+// The owner is only loaded when the user in context does not own the account.
+func (p *ownershipPolicy) authorizeAccount(ctx context.Context, db database.Executor, account *entities.Account) error {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if userCtx.UserID == account.UserID {
+		return nil
+	}
+
+	if role := entities.User_Role(userCtx.Role); role == entities.SuperAdminRole || role == entities.AdminRole {
+		owner, err := p.userRepo.GetUserByID(ctx, db, account.UserID)
+		if err != nil {
+			return err
+		}
+
+		if canManage(role, owner.Role) {
+			return nil
+		}
+	}
+
+	return xerrors.PermissionDenied("not allowed to access account %d", account.ID).WithReason(reasonNotResourceOwner)
+}
+
+// canManage reports whether a user with role may act on resources of another user with target role.
+func canManage(role, target entities.User_Role) bool {
+	switch role {
+	case entities.SuperAdminRole:
+		return true
+	case entities.AdminRole:
+		return target != entities.SuperAdminRole
+	default:
+		return false
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/pkg/cache"
+	"user-management/pkg/database"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+// TokenRevocationService is a service exporter to token revocation for other layers.
+type TokenRevocationService interface {
+	IsRevoked(ctx context.Context, info *xcontext.UserInfo) (bool, error)
+	RevokeToken(ctx context.Context, info *xcontext.UserInfo) error
+	RevokeUserTokens(ctx context.Context, userID int64) error
+}
+
+// tokenRevocationService is a representation of service that implements business logic for revoking tokens.
+// Revocations are stored in postgres and cached (including "not revoked" results), so the authenticate
+// middleware does not hit database for every request.
+type tokenRevocationService struct {
+	pgClient *postgres_client.PostgresClient
+
+	// revocationCache keeps the revoked time by token key and the revoked before time by user key,
+	// a zero time means not revoked.
+	revocationCache cache.Cache[string, time.Time]
+
+	tokenRevocationRepo interface {
+		RevokeToken(ctx context.Context, db database.Executor, data *entities.RevokedToken) (bool, error)
+		GetRevokedToken(ctx context.Context, db database.Executor, tokenID string) (*entities.RevokedToken, error)
+		RevokeUserTokens(ctx context.Context, db database.Executor, data *entities.UserTokenRevocation) error
+		GetUserTokenRevocation(ctx context.Context, db database.Executor, userID int64) (*entities.UserTokenRevocation, error)
+	}
+	refreshTokenRepo interface {
+		RevokeByUserID(ctx context.Context, db database.Executor, userID int64) error
+	}
+}
+
+func NewTokenRevocationService(
+	pgClient *postgres_client.PostgresClient,
+	revocationCache cache.Cache[string, time.Time],
+) TokenRevocationService {
+	return &tokenRevocationService{
+		pgClient:        pgClient,
+		revocationCache: revocationCache,
+
+		// for repositories
+		tokenRevocationRepo: repositories.NewTokenRevocationRepository(),
+		refreshTokenRepo:    repositories.NewRefreshTokenRepository(),
+	}
+}
+
+// IsRevoked returns true if the token itself or all tokens of its user were revoked.
+func (s *tokenRevocationService) IsRevoked(ctx context.Context, info *xcontext.UserInfo) (bool, error) {
+	if info.TokenID != "" {
+		revokedAt, err := s.tokenRevokedAt(ctx, info.TokenID)
+		if err != nil {
+			return false, err
+		}
+
+		if !revokedAt.IsZero() {
+			return true, nil
+		}
+	}
+
+	revokedBefore, err := s.userTokensRevokedBefore(ctx, info.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return !revokedBefore.IsZero() && !info.IssuedAt.After(revokedBefore), nil
+}
+
+// RevokeToken revokes a single token until it expires.
+func (s *tokenRevocationService) RevokeToken(ctx context.Context, info *xcontext.UserInfo) error {
+	if info.TokenID == "" {
+		return xerrors.InvalidArgument("token does not have id")
+	}
+
+	if _, err := s.tokenRevocationRepo.RevokeToken(ctx, s.pgClient, &entities.RevokedToken{
+		TokenID:   info.TokenID,
+		UserID:    info.UserID,
+		ExpiredAt: info.ExpiredAt,
+	}); err != nil {
+		return err
+	}
+
+	s.revocationCache.Add(ctx, tokenRevocationKey(info.TokenID), time.Now())
+
+	return nil
+}
+
+// RevokeUserTokens revokes all access tokens and refresh tokens which were issued to user until now.
+func (s *tokenRevocationService) RevokeUserTokens(ctx context.Context, userID int64) error {
+	// round up to microsecond which is the precision of postgres timestamp,
+	// so tokens issued in the same microsecond are revoked too.
+	revokedBefore := time.Now().Add(time.Microsecond).Truncate(time.Microsecond)
+
+	if err := s.tokenRevocationRepo.RevokeUserTokens(ctx, s.pgClient, &entities.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: revokedBefore,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeByUserID(ctx, s.pgClient, userID); err != nil {
+		return err
+	}
+
+	s.revocationCache.Add(ctx, userRevocationKey(userID), revokedBefore)
+
+	return nil
+}
+
+// tokenRevokedAt returns the revoked time of token, zero if token was not revoked.
+func (s *tokenRevocationService) tokenRevokedAt(ctx context.Context, tokenID string) (time.Time, error) {
+	key := tokenRevocationKey(tokenID)
+	if revokedAt, err := s.revocationCache.Get(ctx, key); err == nil {
+		return revokedAt, nil
+	}
+
+	var revokedAt time.Time
+	token, err := s.tokenRevocationRepo.GetRevokedToken(ctx, s.pgClient, tokenID)
+	switch {
+	case err == nil:
+		revokedAt = token.RevokedAt.Time
+	case !xerrors.IsKind(err, xerrors.KindNotFound):
+		return time.Time{}, err
+	}
+
+	s.revocationCache.Add(ctx, key, revokedAt)
+
+	return revokedAt, nil
+}
+
+// userTokensRevokedBefore returns the time that all tokens of user issued before are revoked, zero if none.
+func (s *tokenRevocationService) userTokensRevokedBefore(ctx context.Context, userID int64) (time.Time, error) {
+	key := userRevocationKey(userID)
+	if revokedBefore, err := s.revocationCache.Get(ctx, key); err == nil {
+		return revokedBefore, nil
+	}
+
+	var revokedBefore time.Time
+	revocation, err := s.tokenRevocationRepo.GetUserTokenRevocation(ctx, s.pgClient, userID)
+	switch {
+	case err == nil:
+		revokedBefore = revocation.RevokedBefore
+	case !xerrors.IsKind(err, xerrors.KindNotFound):
+		return time.Time{}, err
+	}
+
+	s.revocationCache.Add(ctx, key, revokedBefore)
+
+	return revokedBefore, nil
+}
+
+func tokenRevocationKey(tokenID string) string {
+	return fmt.Sprintf("token:%s", tokenID)
+}
+
+func userRevocationKey(userID int64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
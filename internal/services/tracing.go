@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+
+	"user-management/internal/entities"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/xerrors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "user-management/internal/services"
+
+// startSpan starts an internal span named by service and method, the returned function ends the span with err.
+// Only internal errors fail the span, other kinds are expected results of services.
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("error.kind", xerrors.KindOf(err).String()))
+			if xerrors.KindOf(err) == xerrors.KindInternal {
+				span.SetStatus(codes.Error, err.Error())
+			}
+		}
+		span.End()
+	}
+}
+
+// tracingUserService is a decorator of [UserService] recording a span per call.
+type tracingUserService struct {
+	next   UserService
+	tracer trace.Tracer
+}
+
+// WithUserTracing returns a [UserService] recording a span per call of next by the global tracer provider of otel.
+func WithUserTracing(next UserService) UserService {
+	return &tracingUserService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingUserService) CreateUser(ctx context.Context, data *entities.User) (id int64, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.CreateUser")
+	defer func() { end(err) }()
+
+	return s.next.CreateUser(ctx, data)
+}
+
+func (s *tracingUserService) GetUserByID(ctx context.Context, id int64) (user *entities.UserWithAccounts, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.GetUserByID", attribute.Int64("user.id", id))
+	defer func() { end(err) }()
+
+	return s.next.GetUserByID(ctx, id)
+}
+
+func (s *tracingUserService) Update(ctx context.Context, data *entities.User) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.Update", attribute.Int64("user.id", data.ID))
+	defer func() { end(err) }()
+
+	return s.next.Update(ctx, data)
+}
+
+func (s *tracingUserService) DeleteByID(ctx context.Context, id int64) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.DeleteByID", attribute.Int64("user.id", id))
+	defer func() { end(err) }()
+
+	return s.next.DeleteByID(ctx, id)
+}
+
+func (s *tracingUserService) PurgeByID(ctx context.Context, id int64) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.PurgeByID", attribute.Int64("user.id", id))
+	defer func() { end(err) }()
+
+	return s.next.PurgeByID(ctx, id)
+}
+
+func (s *tracingUserService) CreateAccount(ctx context.Context, data *entities.Account) (id int64, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.CreateAccount", attribute.Int64("user.id", data.UserID))
+	defer func() { end(err) }()
+
+	return s.next.CreateAccount(ctx, data)
+}
+
+func (s *tracingUserService) ListAccountByID(ctx context.Context, id int64) (accounts []*entities.Account, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.ListAccountByID", attribute.Int64("user.id", id))
+	defer func() { end(err) }()
+
+	return s.next.ListAccountByID(ctx, id)
+}
+
+func (s *tracingUserService) GetNetWorth(ctx context.Context, id int64, currency string) (worth int64, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "UserService.GetNetWorth", attribute.Int64("user.id", id), attribute.String("currency", currency))
+	defer func() { end(err) }()
+
+	return s.next.GetNetWorth(ctx, id, currency)
+}
+
+// tracingAuthService is a decorator of [AuthService] recording a span per call.
+type tracingAuthService struct {
+	next   AuthService
+	tracer trace.Tracer
+}
+
+// WithAuthTracing returns an [AuthService] recording a span per call of next by the global tracer provider of otel.
+// Credentials and tokens are never recorded.
+func WithAuthTracing(next AuthService) AuthService {
+	return &tracingAuthService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingAuthService) Login(ctx context.Context, data *entities.User) (result *entities.LoginResult, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AuthService.Login")
+	defer func() { end(err) }()
+
+	return s.next.Login(ctx, data)
+}
+
+func (s *tracingAuthService) LoginMFA(ctx context.Context, challengeToken, code string) (user *entities.User, pair *entities.TokenPair, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AuthService.LoginMFA")
+	defer func() { end(err) }()
+
+	return s.next.LoginMFA(ctx, challengeToken, code)
+}
+
+func (s *tracingAuthService) Refresh(ctx context.Context, refreshToken string) (pair *entities.TokenPair, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AuthService.Refresh")
+	defer func() { end(err) }()
+
+	return s.next.Refresh(ctx, refreshToken)
+}
+
+func (s *tracingAuthService) Logout(ctx context.Context, refreshToken string) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AuthService.Logout")
+	defer func() { end(err) }()
+
+	return s.next.Logout(ctx, refreshToken)
+}
+
+func (s *tracingAuthService) Unlock(ctx context.Context, userName string) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AuthService.Unlock")
+	defer func() { end(err) }()
+
+	return s.next.Unlock(ctx, userName)
+}
+
+// tracingMFAService is a decorator of [MFAService] recording a span per call.
+type tracingMFAService struct {
+	next   MFAService
+	tracer trace.Tracer
+}
+
+// WithMFATracing returns a [MFAService] recording a span per call of next by the global tracer provider of otel.
+// Secrets and codes are never recorded.
+func WithMFATracing(next MFAService) MFAService {
+	return &tracingMFAService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingMFAService) Enroll(ctx context.Context) (enrollment *entities.MFAEnrollment, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "MFAService.Enroll")
+	defer func() { end(err) }()
+
+	return s.next.Enroll(ctx)
+}
+
+func (s *tracingMFAService) Activate(ctx context.Context, code string) (recoveryCodes []string, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "MFAService.Activate")
+	defer func() { end(err) }()
+
+	return s.next.Activate(ctx, code)
+}
+
+func (s *tracingMFAService) Reset(ctx context.Context, userID int64) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "MFAService.Reset", attribute.Int64("user.id", userID))
+	defer func() { end(err) }()
+
+	return s.next.Reset(ctx, userID)
+}
+
+func (s *tracingMFAService) Enabled(ctx context.Context, userID int64) (enabled bool, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "MFAService.Enabled", attribute.Int64("user.id", userID))
+	defer func() { end(err) }()
+
+	return s.next.Enabled(ctx, userID)
+}
+
+func (s *tracingMFAService) Verify(ctx context.Context, userID int64, code string) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "MFAService.Verify", attribute.Int64("user.id", userID))
+	defer func() { end(err) }()
+
+	return s.next.Verify(ctx, userID, code)
+}
+
+// tracingAccountService is a decorator of [AccountService] recording a span per call.
+type tracingAccountService struct {
+	next   AccountService
+	tracer trace.Tracer
+}
+
+// WithAccountTracing returns an [AccountService] recording a span per call of next by the global tracer provider of otel.
+func WithAccountTracing(next AccountService) AccountService {
+	return &tracingAccountService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingAccountService) GetAccountByID(ctx context.Context, id int64) (account *entities.Account, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AccountService.GetAccountByID", attribute.Int64("account.id", id))
+	defer func() { end(err) }()
+
+	return s.next.GetAccountByID(ctx, id)
+}
+
+func (s *tracingAccountService) ListTransactionByAccountID(ctx context.Context, id int64, filter *entities.TransactionFilter) (transactions []*entities.Transaction, more bool, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AccountService.ListTransactionByAccountID", attribute.Int64("account.id", id))
+	defer func() { end(err) }()
+
+	return s.next.ListTransactionByAccountID(ctx, id, filter)
+}
+
+func (s *tracingAccountService) Update(ctx context.Context, data *entities.Account) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AccountService.Update", attribute.Int64("account.id", data.ID))
+	defer func() { end(err) }()
+
+	return s.next.Update(ctx, data)
+}
+
+func (s *tracingAccountService) DeleteByID(ctx context.Context, id int64) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "AccountService.DeleteByID", attribute.Int64("account.id", id))
+	defer func() { end(err) }()
+
+	return s.next.DeleteByID(ctx, id)
+}
+
+// tracingTransferService is a decorator of [TransferService] recording a span per call.
+type tracingTransferService struct {
+	next   TransferService
+	tracer trace.Tracer
+}
+
+// WithTransferTracing returns a [TransferService] recording a span per call of next by the global tracer provider of otel.
+func WithTransferTracing(next TransferService) TransferService {
+	return &tracingTransferService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingTransferService) Transfer(ctx context.Context, data *entities.Transfer) (id int64, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "TransferService.Transfer")
+	defer func() { end(err) }()
+
+	return s.next.Transfer(ctx, data)
+}
+
+// tracingTokenRevocationService is a decorator of [TokenRevocationService] recording a span per call.
+type tracingTokenRevocationService struct {
+	next   TokenRevocationService
+	tracer trace.Tracer
+}
+
+// WithTokenRevocationTracing returns a [TokenRevocationService] recording a span per call of next by the global tracer provider of otel.
+func WithTokenRevocationTracing(next TokenRevocationService) TokenRevocationService {
+	return &tracingTokenRevocationService{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *tracingTokenRevocationService) IsRevoked(ctx context.Context, info *xcontext.UserInfo) (revoked bool, err error) {
+	ctx, end := startSpan(ctx, s.tracer, "TokenRevocationService.IsRevoked")
+	defer func() { end(err) }()
+
+	return s.next.IsRevoked(ctx, info)
+}
+
+func (s *tracingTokenRevocationService) RevokeToken(ctx context.Context, info *xcontext.UserInfo) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "TokenRevocationService.RevokeToken")
+	defer func() { end(err) }()
+
+	return s.next.RevokeToken(ctx, info)
+}
+
+func (s *tracingTokenRevocationService) RevokeUserTokens(ctx context.Context, userID int64) (err error) {
+	ctx, end := startSpan(ctx, s.tracer, "TokenRevocationService.RevokeUserTokens", attribute.Int64("user.id", userID))
+	defer func() { end(err) }()
+
+	return s.next.RevokeUserTokens(ctx, userID)
+}
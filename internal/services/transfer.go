@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/internal/repositories"
+	"user-management/pkg/database"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/id_utils"
+	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
+)
+
+// TransferService is a service exporter to transfer for other layers.
+type TransferService interface {
+	Transfer(context.Context, *entities.Transfer) (int64, error)
+}
+
+// transferService is a representation of service that implements business logic for transfer domain.
+type transferService struct {
+	pgClient    *postgres_client.PostgresClient
+	idGenerator id_utils.IDGenerator
+
+	policy *ownershipPolicy
+
+	accountRepo interface {
+		GetAccountByIDForUpdate(ctx context.Context, db database.Executor, id int64) (*entities.Account, error)
+		AddBalanceByID(ctx context.Context, db database.Executor, id int64, amount int64) error
+	}
+	transferRepo interface {
+		Create(ctx context.Context, db database.Executor, data *entities.Transfer) error
+	}
+	ledgerRepo interface {
+		Create(ctx context.Context, db database.Executor, data *entities.LedgerEntry) error
+	}
+	transactionRepo interface {
+		Create(ctx context.Context, db database.Executor, data *entities.Transaction) error
+	}
+}
+
+func NewTransferService(
+	pgClient *postgres_client.PostgresClient,
+	idGenerator id_utils.IDGenerator,
+	changes repositories.ChangePublisher,
+) TransferService {
+	return &transferService{
+		pgClient:    pgClient,
+		idGenerator: idGenerator,
+		policy:      newOwnershipPolicy(),
+
+		// for repositories
+		accountRepo:     repositories.NewAccountRepository(changes),
+		transferRepo:    repositories.NewTransferRepository(),
+		ledgerRepo:      repositories.NewLedgerRepository(),
+		transactionRepo: repositories.NewTransactionRepository(),
+	}
+}
+
+// Transfer is implementation to business logic for moving money between two accounts.
+// The transfer, its ledger entries and both balances are written in a single transaction.
+func (s *transferService) Transfer(ctx context.Context, data *entities.Transfer) (int64, error) {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if data.Amount <= 0 {
+		return 0, xerrors.InvalidArgument("amount must be positive")
+	}
+
+	if data.FromAccountID == data.ToAccountID {
+		return 0, xerrors.InvalidArgument("unable to transfer to the same account")
+	}
+
+	now := time.Now()
+	data.ID = s.idGenerator.Int64()
+	data.CreatedBy = userCtx.UserID
+	data.CreatedAt = database.NullTime(now)
+
+	entries := []*entities.LedgerEntry{
+		{
+			ID:         s.idGenerator.Int64(),
+			TransferID: data.ID,
+			AccountID:  data.FromAccountID,
+			Direction:  entities.DebitDirection,
+			Amount:     data.Amount,
+			CreatedAt:  database.NullTime(now),
+		},
+		{
+			ID:         s.idGenerator.Int64(),
+			TransferID: data.ID,
+			AccountID:  data.ToAccountID,
+			Direction:  entities.CreditDirection,
+			Amount:     data.Amount,
+			CreatedAt:  database.NullTime(now),
+		},
+	}
+
+	accounts := make(map[int64]*entities.Account, 2)
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		// always lock rows by ascending id, so two opposite transfers can not deadlock each other.
+		ids := []int64{data.FromAccountID, data.ToAccountID}
+		slices.Sort(ids)
+		for _, id := range ids {
+			account, err := s.accountRepo.GetAccountByIDForUpdate(ctx, tx, id)
+			if err != nil {
+				if xerrors.IsKind(err, xerrors.KindNotFound) {
+					return xerrors.NotFound("account %d does not exists", id).WithReason(reasonAccountNotFound)
+				}
+				return err
+			}
+			accounts[id] = account
+		}
+
+		// only the owner of the source account can move money out of it.
+		if err := s.policy.authorizeAccount(ctx, tx, accounts[data.FromAccountID]); err != nil {
+			return err
+		}
+
+		if err := checkCanSend(accounts[data.FromAccountID]); err != nil {
+			return err
+		}
+
+		if err := checkCanReceive(accounts[data.ToAccountID]); err != nil {
+			return err
+		}
+
+		if accounts[data.FromAccountID].Currency != accounts[data.ToAccountID].Currency {
+			return xerrors.InvalidArgument("unable to transfer between accounts with different currencies").WithReason(reasonCurrencyMismatch)
+		}
+
+		if accounts[data.FromAccountID].Balance.Int64 < data.Amount {
+			return xerrors.Conflict("insufficient funds").WithReason(reasonInsufficientFunds)
+		}
+
+		if err := s.transferRepo.Create(ctx, tx, data); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := s.ledgerRepo.Create(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			if err := s.accountRepo.AddBalanceByID(ctx, tx, entry.AccountID, entry.SignedAmount()); err != nil {
+				return err
+			}
+
+			// post the entry to account history with the running balance after applied.
+			counterpartyID := data.ToAccountID
+			if entry.AccountID == data.ToAccountID {
+				counterpartyID = data.FromAccountID
+			}
+			if err := s.transactionRepo.Create(ctx, tx, &entities.Transaction{
+				ID:                    s.idGenerator.Int64(),
+				AccountID:             entry.AccountID,
+				TransferID:            data.ID,
+				Direction:             entry.Direction,
+				Amount:                entry.Amount,
+				Description:           data.Description,
+				CounterpartyAccountID: database.NullInt64(counterpartyID),
+				BalanceAfter:          accounts[entry.AccountID].Balance.Int64 + entry.SignedAmount(),
+				PostedAt:              now,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
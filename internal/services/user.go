@@ -2,18 +2,18 @@ package services
 
 import (
 	"context"
-	"database/sql"
-	"errors"
-	"fmt"
+	"math/big"
 
 	"user-management/internal/entities"
 	"user-management/internal/repositories"
 	"user-management/pkg/cache"
 	"user-management/pkg/crypto_utils"
+	"user-management/pkg/currency"
 	"user-management/pkg/database"
 	"user-management/pkg/http_server/xcontext"
 	"user-management/pkg/id_utils"
 	"user-management/pkg/postgres_client"
+	"user-management/pkg/xerrors"
 )
 
 // UserService is a service exporter to used for other layers.
@@ -21,50 +21,70 @@ type UserService interface {
 	CreateUser(context.Context, *entities.User) (int64, error)
 	GetUserByID(context.Context, int64) (*entities.UserWithAccounts, error)
 	Update(ctx context.Context, data *entities.User) error
+	DeleteByID(ctx context.Context, id int64) error
+	PurgeByID(ctx context.Context, id int64) error
 
 	// for account
 	CreateAccount(ctx context.Context, data *entities.Account) (int64, error)
 	ListAccountByID(ctx context.Context, id int64) ([]*entities.Account, error)
+	GetNetWorth(ctx context.Context, id int64, currency string) (int64, error)
 }
 
 // userService is a representation of service that implements business logic for user domain.
 type userService struct {
-	pgClient    *postgres_client.PostgresClient
-	idGenerator id_utils.IDGenerator
+	pgClient       *postgres_client.PostgresClient
+	idGenerator    id_utils.IDGenerator
+	passwordHasher crypto_utils.PasswordHasher
 
-	// using memories cache for user entity
-	userCache           cache.Cache[int64, *entities.UserWithAccounts]
-	userByUserNameCache cache.Cache[string, *entities.User]
+	// using memories cache for user entity, cached values are removed by changes of repositories.
+	userCache           cache.LoadingCache[int64, *entities.UserWithAccounts]
+	userByUserNameCache cache.LoadingCache[string, *entities.User]
+
+	tokenRevocationService TokenRevocationService
+	policy                 *ownershipPolicy
 
 	userRepo interface {
 		Create(ctx context.Context, db database.Executor, data *entities.User) error
 		UpdateByID(ctx context.Context, db database.Executor, id int64, data *entities.User) error
 		GetUserByID(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error)
+		GetUserByIDWithDeleted(ctx context.Context, db database.Executor, id int64) (*entities.UserWithAccounts, error)
 		GetUserByUserName(ctx context.Context, db database.Executor, userName string) (*entities.User, error)
 		DeleteByID(ctx context.Context, db database.Executor, id int64) error
+		PurgeByID(ctx context.Context, db database.Executor, id int64) error
+		CountByRole(ctx context.Context, db database.Executor, role entities.User_Role) (int64, error)
 	}
 	accountRepo interface {
 		Create(ctx context.Context, db database.Executor, data *entities.Account) error
 		ListAccountByUserID(ctx context.Context, db database.Executor, userID int64) ([]*entities.Account, error)
+		FreezeByUserID(ctx context.Context, db database.Executor, userID int64) error
+	}
+	exchangeRateRepo interface {
+		GetRate(ctx context.Context, db database.Executor, base, quote string) (*entities.ExchangeRate, error)
 	}
 }
 
 func NewUserService(
 	pgClient *postgres_client.PostgresClient,
 	idGenerator id_utils.IDGenerator,
-	userCache cache.Cache[int64, *entities.UserWithAccounts],
-	userByUserNameCache cache.Cache[string, *entities.User],
-
+	passwordHasher crypto_utils.PasswordHasher,
+	userCache cache.LoadingCache[int64, *entities.UserWithAccounts],
+	userByUserNameCache cache.LoadingCache[string, *entities.User],
+	changes repositories.ChangePublisher,
+	tokenRevocationService TokenRevocationService,
 ) UserService {
 	return &userService{
-		pgClient:            pgClient,
-		idGenerator:         idGenerator,
-		userCache:           userCache,
-		userByUserNameCache: userByUserNameCache,
+		pgClient:               pgClient,
+		idGenerator:            idGenerator,
+		passwordHasher:         passwordHasher,
+		userCache:              userCache,
+		userByUserNameCache:    userByUserNameCache,
+		tokenRevocationService: tokenRevocationService,
+		policy:                 newOwnershipPolicy(),
 
 		// for repositories
-		userRepo:    repositories.NewUserRepository(),
-		accountRepo: repositories.NewAccountRepository(),
+		userRepo:         repositories.NewUserRepository(changes),
+		accountRepo:      repositories.NewAccountRepository(changes),
+		exchangeRateRepo: repositories.NewExchangeRateRepository(),
 	}
 }
 
@@ -75,22 +95,26 @@ func (s *userService) CreateUser(ctx context.Context, data *entities.User) (int6
 		return 0, err
 	}
 
+	if !canManage(entities.User_Role(userCtx.Role), data.Role) {
+		return 0, xerrors.PermissionDenied("not allowed to create user with role %s", data.Role).WithReason(reasonRoleChangeDenied)
+	}
+
 	data.CreatedBy = userCtx.UserID
 	if existedUser, _ := s.userByUserNameCache.Get(ctx, data.UserName); existedUser != nil {
-		return 0, fmt.Errorf("username already exists")
+		return 0, xerrors.Conflict("username already exists").WithReason(reasonUserNameAlreadyExists)
 	}
 
 	// If user exists we should return an existed user error
 	existedUser, err := s.userRepo.GetUserByUserName(ctx, s.pgClient, data.UserName)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	if err != nil && !xerrors.IsKind(err, xerrors.KindNotFound) {
 		return 0, err
 	}
 	if existedUser != nil {
-		return 0, fmt.Errorf("username already exists")
+		return 0, xerrors.Conflict("username already exists").WithReason(reasonUserNameAlreadyExists)
 	}
 
 	// We should storing a hashed password to user table
-	pwd, err := crypto_utils.HashPassword(data.Password)
+	pwd, err := s.passwordHasher.Hash(data.Password)
 	if err != nil {
 		return 0, err
 	}
@@ -108,18 +132,21 @@ func (s *userService) CreateUser(ctx context.Context, data *entities.User) (int6
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id int64) (*entities.UserWithAccounts, error) {
-	// If user exists in cache, we no need call to database.
-	if data, err := s.userCache.Get(ctx, id); err == nil {
-		return data, nil
-	}
-
-	data, err := s.userRepo.GetUserByID(ctx, s.pgClient, id)
+	// If user exists in cache, we no need call to database, concurrent misses share a single query.
+	data, err := s.userCache.GetOrLoad(ctx, id, func(ctx context.Context) (*entities.UserWithAccounts, error) {
+		return s.userRepo.GetUserByID(ctx, s.pgClient, id)
+	})
 	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+		}
 		return nil, err
 	}
 
-	// cache user entity by id
-	s.userCache.Add(ctx, id, data)
+	// cached users are shared by all callers, so the owner is checked on every read.
+	if err := s.policy.authorizeUser(ctx, &data.User); err != nil {
+		return nil, err
+	}
 
 	return data, nil
 }
@@ -128,34 +155,145 @@ func (s *userService) GetUserByID(ctx context.Context, id int64) (*entities.User
 func (s *userService) Update(ctx context.Context, data *entities.User) error {
 	oldUser, err := s.userRepo.GetUserByID(ctx, s.pgClient, data.ID)
 	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+		}
+		return err
+	}
+
+	if err := s.policy.authorizeUser(ctx, &oldUser.User); err != nil {
 		return err
 	}
+
+	roleChanged := data.Role != "" && data.Role != oldUser.Role
+	if roleChanged {
+		if err := checkChangeRole(ctx, oldUser.Role, data.Role); err != nil {
+			return err
+		}
+
+		if err := s.checkLastSuperAdmin(ctx, s.pgClient, &oldUser.User); err != nil {
+			return err
+		}
+	}
+
 	// just update, no need check exists because we will check row affected.
 	if err := s.userRepo.UpdateByID(ctx, s.pgClient, data.ID, data); err != nil {
 		return err
 	}
 
-	// remove from cache because user info changed
-	s.userCache.Remove(ctx, data.ID)
-	s.userByUserNameCache.Remove(ctx, oldUser.UserName)
+	// tokens carry the role, so tokens issued with the old role must not be accepted anymore.
+	if roleChanged {
+		if err := s.tokenRevocationService.RevokeUserTokens(ctx, data.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkChangeRole returns an error if the user in context is not allowed to change role from old to new.
+// Only admins can change roles and only super admins can grant or take away the super admin role.
+func checkChangeRole(ctx context.Context, oldRole, newRole entities.User_Role) error {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	role := entities.User_Role(userCtx.Role)
+	if !canManage(role, oldRole) || !canManage(role, newRole) {
+		return xerrors.PermissionDenied("not allowed to change role from %s to %s", oldRole, newRole).WithReason(reasonRoleChangeDenied)
+	}
 
 	return nil
 }
 
-// DeleteByID is representation of business logic to update user by id
+// DeleteByID is representation of business logic to soft delete user by id.
+// The accounts of user are frozen and all of its tokens are revoked.
 func (s *userService) DeleteByID(ctx context.Context, id int64) error {
-	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, id)
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		user, err := s.userRepo.GetUserByID(ctx, tx, id)
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+			}
+			return err
+		}
+
+		if err := s.policy.authorizeUser(ctx, &user.User); err != nil {
+			return err
+		}
+
+		if err := s.checkLastSuperAdmin(ctx, tx, &user.User); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.DeleteByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		return s.accountRepo.FreezeByUserID(ctx, tx, id)
+	}); err != nil {
+		return err
+	}
+
+	return s.tokenRevocationService.RevokeUserTokens(ctx, id)
+}
+
+// PurgeByID is representation of business logic to permanently delete user by id, including a soft deleted user.
+// Only super admins can purge users and users having accounts with transfers can not be purged.
+func (s *userService) PurgeByID(ctx context.Context, id int64) error {
+	userCtx, err := xcontext.ExtractUserInfoFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err := s.userRepo.DeleteByID(ctx, s.pgClient, id); err != nil {
+	if entities.User_Role(userCtx.Role) != entities.SuperAdminRole {
+		return xerrors.PermissionDenied("only super admins can purge users")
+	}
+
+	if err := s.pgClient.Transaction(ctx, func(ctx context.Context, tx database.Executor) error {
+		user, err := s.userRepo.GetUserByIDWithDeleted(ctx, tx, id)
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				return xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
+			}
+			return err
+		}
+
+		if err := s.checkLastSuperAdmin(ctx, tx, &user.User); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.PurgeByID(ctx, tx, id); err != nil {
+			// transfers and ledger entries are kept forever, so they still reference accounts of user.
+			if xerrors.IsKind(err, xerrors.KindInvalidArgument) {
+				return xerrors.Conflict("user has accounts with transfers").WithReason(reasonUserHasTransfers)
+			}
+			return err
+		}
+
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	// remove from cache because user info removed
-	s.userCache.Remove(ctx, id)
-	s.userByUserNameCache.Remove(ctx, user.UserName)
+	return s.tokenRevocationService.RevokeUserTokens(ctx, id)
+}
+
+// checkLastSuperAdmin returns an error if user is the last super admin which is not deleted.
+func (s *userService) checkLastSuperAdmin(ctx context.Context, db database.Executor, user *entities.User) error {
+	if user.Role != entities.SuperAdminRole || user.DeletedAt.Valid {
+		return nil
+	}
+
+	count, err := s.userRepo.CountByRole(ctx, db, entities.SuperAdminRole)
+	if err != nil {
+		return err
+	}
+
+	if count <= 1 {
+		return xerrors.Conflict("unable to remove the last super admin").WithReason(reasonLastSuperAdmin)
+	}
 
 	return nil
 }
@@ -163,39 +301,55 @@ func (s *userService) DeleteByID(ctx context.Context, id int64) error {
 // CreateAccount is implementation to business logic for create account by user id.
 func (s *userService) CreateAccount(ctx context.Context, data *entities.Account) (int64, error) {
 	// checking use existed
-	if _, err := s.userRepo.GetUserByID(ctx, s.pgClient, data.UserID); err != nil {
+	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, data.UserID)
+	if err != nil {
 		// custom exists user error
-		if errors.Is(err, sql.ErrNoRows) {
-			return 0, fmt.Errorf("user does not exists")
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return 0, xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
 		}
 
 		// throw err for other error
 		return 0, err
 	}
+
+	if err := s.policy.authorizeUser(ctx, &user.User); err != nil {
+		return 0, err
+	}
+
+	if data.Currency == "" {
+		data.Currency = currency.Default
+	}
+	if !currency.IsValid(data.Currency) {
+		return 0, xerrors.InvalidArgument("currency %s is not supported", data.Currency).WithReason(reasonCurrencyNotSupported)
+	}
+
 	// Generate a new id for new accounts
 	data.ID = s.idGenerator.Int64()
+	data.Status = entities.ActiveStatus
 
-	err := s.accountRepo.Create(ctx, s.pgClient, data)
-	if err != nil {
+	if err := s.accountRepo.Create(ctx, s.pgClient, data); err != nil {
 		return 0, err
 	}
 
-	s.userCache.Remove(ctx, data.ID)
-
 	return data.ID, nil
 }
 
 func (s *userService) ListAccountByID(ctx context.Context, id int64) ([]*entities.Account, error) {
 	// checking use existed
-	if _, err := s.userRepo.GetUserByID(ctx, s.pgClient, id); err != nil {
+	user, err := s.userRepo.GetUserByID(ctx, s.pgClient, id)
+	if err != nil {
 		// custom exists user error
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user does not exists")
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("user does not exists").WithReason(reasonUserNotFound)
 		}
 
 		return nil, err
 	}
 
+	if err := s.policy.authorizeUser(ctx, &user.User); err != nil {
+		return nil, err
+	}
+
 	accounts, err := s.accountRepo.ListAccountByUserID(ctx, s.pgClient, id)
 	if err != nil {
 		return nil, err
@@ -204,4 +358,71 @@ func (s *userService) ListAccountByID(ctx context.Context, id int64) ([]*entitie
 	return accounts, nil
 }
 
+// GetNetWorth is implementation to business logic for summing balances of all accounts of user
+// converted into the given currency, the result is in minor units of that currency.
+func (s *userService) GetNetWorth(ctx context.Context, id int64, target string) (int64, error) {
+	if !currency.IsValid(target) {
+		return 0, xerrors.InvalidArgument("currency %s is not supported", target).WithReason(reasonCurrencyNotSupported)
+	}
+
+	// the owner is authorized by listing accounts, so balances of other users are never summed.
+	accounts, err := s.ListAccountByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// sum by currency first, so each currency is converted (and rounded) only once.
+	totals := make(map[string]int64)
+	for _, a := range accounts {
+		totals[a.Currency] += a.Balance.Int64
+	}
+
+	var result int64
+	for code, total := range totals {
+		rate, err := s.getExchangeRate(ctx, code, target)
+		if err != nil {
+			return 0, err
+		}
+
+		converted, err := currency.Convert(total, code, target, rate)
+		if err != nil {
+			return 0, err
+		}
+		result += converted
+	}
+
+	return result, nil
+}
+
+// getExchangeRate returns the rate from base to quote currency, the inverse rate is used
+// when only the opposite pair was loaded.
+func (s *userService) getExchangeRate(ctx context.Context, base, quote string) (*big.Rat, error) {
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, err := s.exchangeRateRepo.GetRate(ctx, s.pgClient, base, quote)
+	if err == nil {
+		return currency.ParseRate(rate.Rate)
+	}
+	if !xerrors.IsKind(err, xerrors.KindNotFound) {
+		return nil, err
+	}
+
+	rate, err = s.exchangeRateRepo.GetRate(ctx, s.pgClient, quote, base)
+	if err != nil {
+		if xerrors.IsKind(err, xerrors.KindNotFound) {
+			return nil, xerrors.NotFound("exchange rate from %s to %s does not exists", base, quote).WithReason(reasonExchangeRateNotFound)
+		}
+		return nil, err
+	}
+
+	inverse, err := currency.ParseRate(rate.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return inverse.Inv(inverse), nil
+}
+
 // For using skeleton: s *userService UserService
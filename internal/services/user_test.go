@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/pkg/cache"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/lru"
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserService(users ...*entities.User) *userService {
+	userRepo := newFakeUserRepo(users...)
+	accountRepo := newFakeAccountRepo()
+	for _, u := range users {
+		_ = accountRepo.Create(context.Background(), nil, &entities.Account{ID: u.ID * 10, UserID: u.ID, Currency: "JPY"})
+	}
+
+	policy := newOwnershipPolicy()
+	policy.userRepo = userRepo
+
+	return &userService{
+		userCache:           cache.NewLoader[int64, *entities.UserWithAccounts](lru.NewLRU[int64, *cache.Entry[*entities.UserWithAccounts]](16, time.Hour)),
+		userByUserNameCache: cache.NewLoader[string, *entities.User](lru.NewLRU[string, *cache.Entry[*entities.User]](16, time.Hour)),
+		policy:              policy,
+		userRepo:            userRepo,
+		accountRepo:         accountRepo,
+	}
+}
+
+func withUser(id int64, role entities.User_Role) context.Context {
+	return xcontext.ImportUserInfoToContext(context.Background(), &xcontext.UserInfo{UserID: id, Role: string(role)})
+}
+
+func TestReadOtherUserIsDenied(t *testing.T) {
+	alice := &entities.User{ID: 1, UserName: "alice", Role: entities.UserRole}
+	bob := &entities.User{ID: 2, UserName: "bob", Role: entities.UserRole}
+	root := &entities.User{ID: 3, UserName: "root", Role: entities.SuperAdminRole}
+	s := newTestUserService(alice, bob, root)
+
+	reads := map[string]func(ctx context.Context, id int64) error{
+		"GetUserByID": func(ctx context.Context, id int64) error {
+			_, err := s.GetUserByID(ctx, id)
+			return err
+		},
+		"ListAccountByID": func(ctx context.Context, id int64) error {
+			_, err := s.ListAccountByID(ctx, id)
+			return err
+		},
+		"GetNetWorth": func(ctx context.Context, id int64) error {
+			_, err := s.GetNetWorth(ctx, id, "JPY")
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, read(withUser(alice.ID, alice.Role), alice.ID), "owner")
+			require.NoError(t, read(withUser(root.ID, root.Role), alice.ID), "super admin")
+
+			err := read(withUser(alice.ID, alice.Role), bob.ID)
+			assert.True(t, xerrors.IsKind(err, xerrors.KindPermissionDenied), err)
+
+			// a cached user is not served to another user either.
+			err = read(withUser(bob.ID, bob.Role), alice.ID)
+			assert.True(t, xerrors.IsKind(err, xerrors.KindPermissionDenied), err)
+		})
+	}
+}
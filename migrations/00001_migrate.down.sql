@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS accounts;
+
+DROP TABLE IF EXISTS users;
+
+DROP TYPE IF EXISTS role_type;
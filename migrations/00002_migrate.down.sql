@@ -0,0 +1 @@
+DROP INDEX IF EXISTS accounts_user_id_idx;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ledger_entries;
+
+DROP TABLE IF EXISTS transfers;
+
+DROP TYPE IF EXISTS ledger_direction_type;
@@ -0,0 +1,29 @@
+CREATE TYPE ledger_direction_type AS ENUM ('DEBIT', 'CREDIT');
+
+-- create transfer table, each transfer moves money between two accounts
+CREATE TABLE IF NOT EXISTS transfers (
+  id BIGINT PRIMARY KEY,
+  from_account_id BIGINT NOT NULL,
+  to_account_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  description TEXT,
+  created_by BIGINT,
+  created_at timestamptz DEFAULT now(),
+  FOREIGN KEY ("from_account_id") REFERENCES "accounts"("id"),
+  FOREIGN KEY ("to_account_id") REFERENCES "accounts"("id")
+);
+
+-- create ledger table, a transfer always produces one debit and one credit entry
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id BIGINT PRIMARY KEY,
+  transfer_id BIGINT NOT NULL,
+  account_id BIGINT NOT NULL,
+  direction ledger_direction_type NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  created_at timestamptz DEFAULT now(),
+  FOREIGN KEY ("transfer_id") REFERENCES "transfers"("id"),
+  FOREIGN KEY ("account_id") REFERENCES "accounts"("id")
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_id_idx ON ledger_entries(account_id);
+CREATE INDEX IF NOT EXISTS ledger_entries_transfer_id_idx ON ledger_entries(transfer_id);
@@ -0,0 +1,18 @@
+-- create transaction table, a posted view of ledger entries per account including running balance
+CREATE TABLE IF NOT EXISTS transactions (
+  id BIGINT PRIMARY KEY,
+  account_id BIGINT NOT NULL,
+  transfer_id BIGINT NOT NULL,
+  direction ledger_direction_type NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  description TEXT,
+  counterparty_account_id BIGINT,
+  balance_after BIGINT NOT NULL,
+  posted_at timestamptz NOT NULL DEFAULT now(),
+  FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON
+  DELETE
+    CASCADE,
+  FOREIGN KEY ("transfer_id") REFERENCES "transfers"("id")
+);
+
+CREATE INDEX IF NOT EXISTS transactions_account_id_posted_at_idx ON transactions(account_id, posted_at DESC, id DESC);
@@ -0,0 +1,11 @@
+-- amounts of accounts are stored in minor units of their currency
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'JPY';
+
+-- create exchange rate table, 1 major unit of base currency equals rate major units of quote currency
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  base_currency CHAR(3) NOT NULL,
+  quote_currency CHAR(3) NOT NULL,
+  rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+  updated_at timestamptz DEFAULT now(),
+  PRIMARY KEY (base_currency, quote_currency)
+);
@@ -0,0 +1,18 @@
+-- create refresh token table, only sha-256 hashes of tokens are stored.
+-- tokens rotated from the same login share a family, reusing a rotated token revokes the whole family.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id BIGINT PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  family_id BIGINT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  expired_at timestamptz NOT NULL,
+  used_at timestamptz,
+  revoked_at timestamptz,
+  created_at timestamptz DEFAULT now(),
+  FOREIGN KEY ("user_id") REFERENCES "users"("id") ON
+  DELETE
+    CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens(user_id);
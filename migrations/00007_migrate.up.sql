@@ -0,0 +1,15 @@
+-- create revoked token table, a single access token revoked by its id (jti) until it expires
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+  token_id TEXT PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  expired_at timestamptz NOT NULL,
+  revoked_at timestamptz DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_expired_at_idx ON revoked_tokens(expired_at);
+
+-- create user token revocation table, all tokens of user issued before revoked_before are revoked
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+  user_id BIGINT PRIMARY KEY,
+  revoked_before timestamptz NOT NULL
+);
@@ -0,0 +1,4 @@
+CREATE TYPE account_status_type AS ENUM ('ACTIVE', 'FROZEN', 'CLOSED');
+
+-- frozen accounts can only receive money, closed accounts can not change their balance anymore
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status account_status_type NOT NULL DEFAULT 'ACTIVE';
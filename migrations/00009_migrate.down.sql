@@ -0,0 +1,13 @@
+-- soft deleted users are never removed by migrating down, they must be purged first,
+-- otherwise their user names could not be unique anymore
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM users WHERE deleted_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'soft deleted users exist, purge them before migrating down';
+  END IF;
+END $$;
+
+DROP INDEX IF EXISTS users_user_name_key;
+ALTER TABLE users ADD CONSTRAINT users_user_name_key UNIQUE (user_name);
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
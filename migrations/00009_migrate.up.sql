@@ -0,0 +1,6 @@
+-- users are soft deleted, a deleted user keeps its row until purged
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+-- user name of a deleted user can be used again
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_user_name_key ON users(user_name) WHERE deleted_at IS NULL;
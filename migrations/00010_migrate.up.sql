@@ -0,0 +1,26 @@
+-- create mfa table, the totp secret is sealed by a key derived from the symmetric key.
+-- enabled_at is null until the enrollment is verified by a code, last_used_step rejects replays of codes.
+CREATE TABLE IF NOT EXISTS user_mfa (
+  user_id BIGINT PRIMARY KEY,
+  secret TEXT NOT NULL,
+  enabled_at timestamptz,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at timestamptz DEFAULT now(),
+  FOREIGN KEY ("user_id") REFERENCES "users"("id") ON
+  DELETE
+    CASCADE
+);
+
+-- create recovery code table, only sha-256 hashes of codes are stored and every code is used once.
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+  id BIGINT PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  code_hash TEXT NOT NULL,
+  used_at timestamptz,
+  created_at timestamptz DEFAULT now(),
+  FOREIGN KEY ("user_id") REFERENCES "users"("id") ON
+  DELETE
+    CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id_idx ON mfa_recovery_codes(user_id);
@@ -0,0 +1,9 @@
+// Package migrations contains sql files to migrate the database schema, they are embedded into the binary.
+package migrations
+
+import "embed"
+
+// FS contains all up and down migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,6 +1,12 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned (wrapped) by Get and Remove when the key does not exist in cache.
+var ErrNotFound = errors.New("cache: value not found")
 
 // Cache is an exporter for common interface of memories caching [user-management/lru.lru]
 // or third party caching like redis.
@@ -9,3 +15,13 @@ type Cache[K comparable, V any] interface {
 	Get(context.Context, K) (V, error)
 	Remove(context.Context, K) error
 }
+
+// Purger is implemented by caches which can remove all of their values at once.
+type Purger interface {
+	Purge(context.Context) error
+}
+
+// Sizer is implemented by caches which can count their values.
+type Sizer interface {
+	Len(context.Context) (int, error)
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"user-management/pkg/xerrors"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc loads the value of a key from the source of truth (ex: database) on a cache miss.
+type LoadFunc[V any] func(ctx context.Context) (V, error)
+
+// LoadingCache is a [Cache] which can also load missing values by itself.
+type LoadingCache[K comparable, V any] interface {
+	Cache[K, V]
+
+	// GetOrLoad returns the cached value of key or calls load to get and cache it.
+	// Concurrent misses of the same key share a single call of load.
+	GetOrLoad(ctx context.Context, k K, load LoadFunc[V]) (V, error)
+}
+
+// Entry is a cached value of [Loader] with the metadata to serve it, it is stored by the underlying cache.
+type Entry[V any] struct {
+	Value V `json:"value"`
+	// NotFound is true if the value was not found by the loader.
+	NotFound bool `json:"not_found,omitempty"`
+	// FreshUntil is the time after that the entry is stale and will be revalidated, zero means always fresh.
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+// Loader is a read-through [LoadingCache] on top of another cache,
+// it prevents the stampede of loading a hot key from the source of truth when the key is missing or stale.
+type Loader[K comparable, V any] struct {
+	cache Cache[K, *Entry[V]]
+	group singleflight.Group
+
+	// generations of keys being loaded, Remove and Purge bump them, so a load which started before
+	// does not cache the value it read before the change.
+	mu          sync.Mutex
+	generations map[K]uint64
+
+	freshFor    time.Duration
+	notFoundTTL time.Duration
+}
+
+// LoaderOption is an option to custom behaviors of [Loader].
+type LoaderOption func(*loaderOptions)
+
+type loaderOptions struct {
+	freshFor    time.Duration
+	notFoundTTL time.Duration
+}
+
+// WithStaleWhileRevalidate makes entries stale after d, a stale entry is still returned
+// while it is reloaded in background until the underlying cache evicts it.
+func WithStaleWhileRevalidate(d time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.freshFor = d
+	}
+}
+
+// WithNotFoundTTL caches a not found result of the loader (an error of [xerrors.KindNotFound]) for ttl,
+// so repeated lookups of a missing key do not reach the source of truth.
+func WithNotFoundTTL(ttl time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.notFoundTTL = ttl
+	}
+}
+
+// NewLoader creates a [Loader] storing entries into the given cache.
+func NewLoader[K comparable, V any](c Cache[K, *Entry[V]], opts ...LoaderOption) *Loader[K, V] {
+	var o loaderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Loader[K, V]{
+		cache:       c,
+		generations: make(map[K]uint64),
+		freshFor:    o.freshFor,
+		notFoundTTL: o.notFoundTTL,
+	}
+}
+
+// Add is implementation of Add by [Loader] in [Cache]
+func (l *Loader[K, V]) Add(ctx context.Context, k K, v V) error {
+	return l.cache.Add(ctx, k, l.newEntry(v))
+}
+
+// Get is implementation of Get by [Loader] in [Cache], a cached not found result is a miss.
+func (l *Loader[K, V]) Get(ctx context.Context, k K) (V, error) {
+	var v V
+	entry, err := l.cache.Get(ctx, k)
+	if err != nil {
+		return v, err
+	}
+
+	if entry.NotFound {
+		return v, fmt.Errorf("value of %v does not exists: %w", k, ErrNotFound)
+	}
+
+	return entry.Value, nil
+}
+
+// Remove is implementation of Remove by [Loader] in [Cache], a load of k in flight will not cache its result.
+func (l *Loader[K, V]) Remove(ctx context.Context, k K) error {
+	l.mu.Lock()
+	if gen, ok := l.generations[k]; ok {
+		l.generations[k] = gen + 1
+	}
+	l.mu.Unlock()
+
+	return l.cache.Remove(ctx, k)
+}
+
+// Purge is implementation of Purge by [Loader] in [Purger], it fails if the underlying cache is not a [Purger].
+func (l *Loader[K, V]) Purge(ctx context.Context) error {
+	purger, ok := l.cache.(Purger)
+	if !ok {
+		return fmt.Errorf("cache %T can not be purged", l.cache)
+	}
+
+	l.mu.Lock()
+	for k, gen := range l.generations {
+		l.generations[k] = gen + 1
+	}
+	l.mu.Unlock()
+
+	return purger.Purge(ctx)
+}
+
+// Len is implementation of Len by [Loader] in [Sizer], it fails if the underlying cache is not a [Sizer].
+func (l *Loader[K, V]) Len(ctx context.Context) (int, error) {
+	sizer, ok := l.cache.(Sizer)
+	if !ok {
+		return 0, fmt.Errorf("cache %T can not be counted", l.cache)
+	}
+
+	return sizer.Len(ctx)
+}
+
+// GetOrLoad is implementation of GetOrLoad by [Loader] in [LoadingCache].
+// A cached not found result returns an error of [xerrors.KindNotFound] wrapping [ErrNotFound].
+func (l *Loader[K, V]) GetOrLoad(ctx context.Context, k K, load LoadFunc[V]) (V, error) {
+	if entry, err := l.cache.Get(ctx, k); err == nil {
+		stale := !entry.FreshUntil.IsZero() && time.Now().After(entry.FreshUntil)
+		switch {
+		case entry.NotFound && stale:
+			// not found results are never served stale, load it again below.
+		case entry.NotFound:
+			var v V
+			return v, xerrors.NotFound("value of %v does not exists: %w", k, ErrNotFound)
+		case stale:
+			// serve the stale entry and refresh it in background without waiting for the result.
+			l.group.DoChan(fmt.Sprint(k), l.loadFunc(ctx, k, load))
+			return entry.Value, nil
+		default:
+			return entry.Value, nil
+		}
+	}
+
+	result, err, _ := l.group.Do(fmt.Sprint(k), l.loadFunc(ctx, k, load))
+
+	return result.(V), err
+}
+
+// loadFunc returns the function shared by concurrent callers of the same key, it calls load and caches the result.
+// The result is only cached if k was not removed while loading, otherwise a value read before a change
+// would be cached after the change was invalidated.
+func (l *Loader[K, V]) loadFunc(ctx context.Context, k K, load LoadFunc[V]) func() (any, error) {
+	return func() (any, error) {
+		// singleflight runs one load of a key at a time, so the generation is only tracked during this call.
+		gen := l.beginLoad(k)
+		defer l.endLoad(k)
+
+		// the result is shared by other callers and background refreshes outlive the request,
+		// so loading must not be canceled with the context of the first caller.
+		ctx := context.WithoutCancel(ctx)
+		v, err := load(ctx)
+		if err != nil {
+			if xerrors.IsKind(err, xerrors.KindNotFound) {
+				if l.notFoundTTL > 0 {
+					l.addIfUnchanged(ctx, k, gen, &Entry[V]{NotFound: true, FreshUntil: time.Now().Add(l.notFoundTTL)})
+				} else {
+					// a stale value of a removed key must not be served anymore.
+					l.cache.Remove(ctx, k)
+				}
+			}
+			return v, err
+		}
+
+		// caching is best-effort, the loaded value is returned anyway.
+		l.addIfUnchanged(ctx, k, gen, l.newEntry(v))
+
+		return v, nil
+	}
+}
+
+// beginLoad starts tracking the generation of k and returns it.
+func (l *Loader[K, V]) beginLoad(k K) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	gen := l.generations[k]
+	l.generations[k] = gen
+
+	return gen
+}
+
+// endLoad stops tracking the generation of k, so only keys being loaded are kept.
+func (l *Loader[K, V]) endLoad(k K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.generations, k)
+}
+
+// changed reports whether k was removed after its load started at gen.
+func (l *Loader[K, V]) changed(k K, gen uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.generations[k] != gen
+}
+
+// addIfUnchanged caches entry of k if k was not removed since gen.
+// A removal between the check and adding is caught by checking again, the entry is removed then.
+func (l *Loader[K, V]) addIfUnchanged(ctx context.Context, k K, gen uint64, entry *Entry[V]) {
+	if l.changed(k, gen) {
+		return
+	}
+
+	l.cache.Add(ctx, k, entry)
+
+	if l.changed(k, gen) {
+		l.cache.Remove(ctx, k)
+	}
+}
+
+func (l *Loader[K, V]) newEntry(v V) *Entry[V] {
+	entry := &Entry[V]{Value: v}
+	if l.freshFor > 0 {
+		entry.FreshUntil = time.Now().Add(l.freshFor)
+	}
+
+	return entry
+}
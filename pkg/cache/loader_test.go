@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/lru"
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoader(opts ...cache.LoaderOption) *cache.Loader[int64, string] {
+	return cache.NewLoader[int64, string](lru.NewLRU[int64, *cache.Entry[string]](16, time.Hour), opts...)
+}
+
+func TestLoaderCoalescesMisses(t *testing.T) {
+	ctx := context.Background()
+	l := newLoader()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "dat", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.GetOrLoad(ctx, 1, load)
+			assert.NoError(t, err)
+			assert.Equal(t, "dat", v)
+		}()
+	}
+
+	// wait until all callers are blocked by the first load.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	v, err := l.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dat", v)
+}
+
+func TestLoaderNotFound(t *testing.T) {
+	ctx := context.Background()
+	l := newLoader(cache.WithNotFoundTTL(time.Hour))
+
+	var calls atomic.Int32
+	load := func(context.Context) (string, error) {
+		calls.Add(1)
+		return "", xerrors.NotFound("user does not exists")
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := l.GetOrLoad(ctx, 1, load)
+		assert.True(t, xerrors.IsKind(err, xerrors.KindNotFound))
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err := l.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	// other errors are not cached.
+	_, err = l.GetOrLoad(ctx, 2, func(context.Context) (string, error) { return "", xerrors.Internal("boom") })
+	assert.True(t, xerrors.IsKind(err, xerrors.KindInternal))
+	_, err = l.Get(ctx, 2)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+}
+
+func TestLoaderStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	l := newLoader(cache.WithStaleWhileRevalidate(time.Millisecond))
+	require.NoError(t, l.Add(ctx, 1, "old"))
+	time.Sleep(5 * time.Millisecond)
+
+	refreshed := make(chan struct{})
+	v, err := l.GetOrLoad(ctx, 1, func(context.Context) (string, error) {
+		defer close(refreshed)
+		return "new", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old", v, "stale value is served while revalidating")
+
+	<-refreshed
+	assert.Eventually(t, func() bool {
+		v, err := l.Get(ctx, 1)
+		return err == nil && v == "new"
+	}, time.Second, time.Millisecond)
+}
+
+func TestLoaderRemoveDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	l := newLoader()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, err := l.GetOrLoad(ctx, 1, func(context.Context) (string, error) {
+			close(started)
+			<-release
+			// the value was read before the change which removed the key.
+			return "old", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "old", v, "the caller still gets the loaded value")
+	}()
+
+	<-started
+	assert.ErrorIs(t, l.Remove(ctx, 1), cache.ErrNotFound, "key is not cached yet")
+	close(release)
+	<-done
+
+	_, err := l.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrNotFound, "value loaded before remove must not be cached")
+
+	// next loads are cached again.
+	v, err := l.GetOrLoad(ctx, 1, func(context.Context) (string, error) { return "new", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "new", v)
+	v, err = l.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "new", v)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"user-management/pkg/xerrors"
+)
+
+// Info describes a registered cache, Size is -1 if the cache can not be counted.
+type Info struct {
+	Name string
+	Size int
+}
+
+// managed is a registered cache with its key type erased, so caches of different types can be managed by name.
+type managed struct {
+	cache  any
+	remove func(ctx context.Context, key string) error
+}
+
+// Registry is a set of named caches which can be listed, purged or have a key removed by operators.
+type Registry struct {
+	caches map[string]*managed
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		caches: make(map[string]*managed),
+	}
+}
+
+// Register adds c to r as name, keys are parsed from string to K when they are removed by name.
+// Caches must be registered before they are managed.
+func Register[K comparable, V any](r *Registry, name string, c Cache[K, V]) {
+	r.caches[name] = &managed{
+		cache: c,
+		remove: func(ctx context.Context, key string) error {
+			k, err := parseKey[K](key)
+			if err != nil {
+				return xerrors.InvalidArgument("key %q is not valid for cache %s: %w", key, name, err)
+			}
+
+			return c.Remove(ctx, k)
+		},
+	}
+}
+
+// List returns all registered caches sorted by name.
+func (r *Registry) List(ctx context.Context) ([]*Info, error) {
+	names := make([]string, 0, len(r.caches))
+	for name := range r.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	infos := make([]*Info, 0, len(names))
+	for _, name := range names {
+		info := &Info{Name: name, Size: -1}
+		if sizer, ok := r.caches[name].cache.(Sizer); ok {
+			size, err := sizer.Len(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to count cache %s: %w", name, err)
+			}
+			info.Size = size
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// Purge removes all values of the cache registered as name.
+func (r *Registry) Purge(ctx context.Context, name string) error {
+	m, err := r.get(name)
+	if err != nil {
+		return err
+	}
+
+	purger, ok := m.cache.(Purger)
+	if !ok {
+		return xerrors.InvalidArgument("cache %s can not be purged", name)
+	}
+
+	return purger.Purge(ctx)
+}
+
+// Remove removes key from the cache registered as name.
+// A missing key is not an error, the cache may be shared with other instances which still have it.
+func (r *Registry) Remove(ctx context.Context, name, key string) error {
+	m, err := r.get(name)
+	if err != nil {
+		return err
+	}
+
+	if err := m.remove(ctx, key); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Registry) get(name string) (*managed, error) {
+	m, ok := r.caches[name]
+	if !ok {
+		return nil, xerrors.NotFound("cache %s is not registered", name)
+	}
+
+	return m, nil
+}
+
+// parseKey parses key to K, string keys are taken as is and other keys are parsed as json (ex: numbers).
+func parseKey[K comparable](key string) (K, error) {
+	var k K
+	if s, ok := any(&k).(*string); ok {
+		*s = key
+		return k, nil
+	}
+
+	err := json.Unmarshal([]byte(key), &k)
+
+	return k, err
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapCache is a [Cache] and [Purger] on top of a map, it is not safe for concurrent use.
+type mapCache[K comparable, V any] map[K]V
+
+func (c mapCache[K, V]) Add(_ context.Context, k K, v V) error {
+	c[k] = v
+	return nil
+}
+
+func (c mapCache[K, V]) Get(_ context.Context, k K) (V, error) {
+	v, ok := c[k]
+	if !ok {
+		return v, fmt.Errorf("value of %v does not exists: %w", k, ErrNotFound)
+	}
+	return v, nil
+}
+
+func (c mapCache[K, V]) Remove(_ context.Context, k K) error {
+	if _, ok := c[k]; !ok {
+		return fmt.Errorf("value of %v does not exists: %w", k, ErrNotFound)
+	}
+	delete(c, k)
+	return nil
+}
+
+func (c mapCache[K, V]) Purge(_ context.Context) error {
+	clear(c)
+	return nil
+}
+
+func (c mapCache[K, V]) Len(_ context.Context) (int, error) {
+	return len(c), nil
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	users := mapCache[int64, string]{1: "dat", 2: "tuan"}
+	userNames := mapCache[string, int64]{"dat": 1}
+	r := NewRegistry()
+	Register[int64, string](r, "user", users)
+	Register[string, int64](r, "user_by_user_name", NewLoader[string, int64](mapCache[string, *Entry[int64]]{}))
+	Register[string, int64](r, "revocation", userNames)
+
+	infos, err := r.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*Info{
+		{Name: "revocation", Size: 1},
+		{Name: "user", Size: 2},
+		{Name: "user_by_user_name", Size: 0},
+	}, infos)
+
+	// keys are parsed to the key type of cache.
+	require.NoError(t, r.Remove(ctx, "user", "1"))
+	assert.NotContains(t, users, int64(1))
+	require.NoError(t, r.Remove(ctx, "revocation", "dat"))
+	assert.Empty(t, userNames)
+
+	assert.NoError(t, r.Remove(ctx, "user", "1"), "missing key is not an error")
+	assert.True(t, xerrors.IsKind(r.Remove(ctx, "user", "dat"), xerrors.KindInvalidArgument))
+	assert.True(t, xerrors.IsKind(r.Remove(ctx, "account", "1"), xerrors.KindNotFound))
+
+	require.NoError(t, r.Purge(ctx, "user"))
+	assert.Empty(t, users)
+	assert.True(t, xerrors.IsKind(r.Purge(ctx, "account"), xerrors.KindNotFound))
+}
@@ -0,0 +1,255 @@
+package crypto_utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"user-management/pkg/xerrors"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrPasswordMismatch is returned by [PasswordHasher.Verify] when the password does not match the hash.
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher hashes passwords into self-describing encoded hashes (ex: PHC strings) and verifies them.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password with a random salt.
+	Hash(password string) (string, error)
+	// Verify returns [ErrPasswordMismatch] if password does not match encoded.
+	// needsRehash is true if encoded was produced by another algorithm or outdated parameters,
+	// so the password should be hashed again while it is known.
+	Verify(password, encoded string) (needsRehash bool, err error)
+}
+
+// Argon2idParams are the cost parameters of argon2id.
+type Argon2idParams struct {
+	// Memory is the memory used by hashing in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams are the minimum parameters recommended by OWASP.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// validate returns an error if a parameter is zero, argon2 would panic or produce weak hashes by it.
+func (p Argon2idParams) validate() error {
+	switch {
+	case p.Memory == 0:
+		return errors.New("memory of argon2id must be greater than zero")
+	case p.Iterations == 0:
+		return errors.New("iterations of argon2id must be greater than zero")
+	case p.Parallelism == 0:
+		return errors.New("parallelism of argon2id must be greater than zero")
+	case p.SaltLength == 0:
+		return errors.New("salt length of argon2id must be greater than zero")
+	case p.KeyLength == 0:
+		return errors.New("key length of argon2id must be greater than zero")
+	}
+
+	return nil
+}
+
+// algorithm is a hash algorithm which can be selected to hash passwords.
+type algorithm interface {
+	hash(password string) (string, error)
+	// matches reports whether encoded is produced by the algorithm.
+	matches(encoded string) bool
+	verify(password, encoded string) (outdated bool, err error)
+}
+
+// passwordHasher hashes by the preferred algorithm and verifies hashes of all known algorithms,
+// so existing hashes keep working after the preferred algorithm is changed.
+type passwordHasher struct {
+	preferred  algorithm
+	algorithms []algorithm
+}
+
+// NewPasswordHasher returns a hasher producing hashes by name ([AlgorithmArgon2id] or [AlgorithmBcrypt])
+// and verifying hashes of both algorithms.
+// Parameters of both algorithms are validated, because hashes of the other one are rehashed by them after switching.
+func NewPasswordHasher(name string, argon2idParams Argon2idParams, bcryptCost int) (PasswordHasher, error) {
+	if err := argon2idParams.validate(); err != nil {
+		return nil, err
+	}
+	// bcrypt silently falls back to its default cost below the minimum.
+	if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("cost of bcrypt must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	argon := &argon2idAlgorithm{params: argon2idParams}
+	bc := &bcryptAlgorithm{cost: bcryptCost}
+
+	h := &passwordHasher{algorithms: []algorithm{argon, bc}}
+	switch name {
+	case AlgorithmArgon2id:
+		h.preferred = argon
+	case AlgorithmBcrypt:
+		h.preferred = bc
+	default:
+		return nil, fmt.Errorf("password hash algorithm %q is not supported", name)
+	}
+
+	return h, nil
+}
+
+// Hash is implementation of Hash by [passwordHasher] in [PasswordHasher]
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.preferred.hash(password)
+}
+
+// Verify is implementation of Verify by [passwordHasher] in [PasswordHasher]
+func (h *passwordHasher) Verify(password, encoded string) (bool, error) {
+	for _, a := range h.algorithms {
+		if !a.matches(encoded) {
+			continue
+		}
+
+		outdated, err := a.verify(password, encoded)
+		if err != nil {
+			return false, err
+		}
+
+		return a != h.preferred || outdated, nil
+	}
+
+	return false, fmt.Errorf("hash algorithm of password is not supported")
+}
+
+// argon2idAlgorithm encodes hashes in PHC string format: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>.
+type argon2idAlgorithm struct {
+	params Argon2idParams
+}
+
+const argon2idPrefix = "$" + AlgorithmArgon2id + "$"
+
+func (a *argon2idAlgorithm) hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		a.params.Memory, a.params.Iterations, a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *argon2idAlgorithm) matches(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (a *argon2idAlgorithm) verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	// the hash is verified by its own parameters, the current parameters only decide whether it is outdated.
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, ErrPasswordMismatch
+	}
+
+	return params != a.params, nil
+}
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, fmt.Errorf("argon2id hash is not valid")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("version of argon2id hash is not valid: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("version %d of argon2id hash is not supported", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("parameters of argon2id hash are not valid: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("salt of argon2id hash is not valid: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("key of argon2id hash is not valid: %w", err)
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// bcryptAlgorithm encodes hashes in modular crypt format: $2a$10$<salt and hash>.
+// Passwords longer than 72 bytes are rejected instead of being truncated.
+type bcryptAlgorithm struct {
+	cost int
+}
+
+func (a *bcryptAlgorithm) hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), a.cost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", xerrors.InvalidArgument("password must not be longer than 72 bytes by bcrypt: %w", err)
+		}
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hashed), nil
+}
+
+func (a *bcryptAlgorithm) matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (a *bcryptAlgorithm) verify(password, encoded string) (bool, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+		// a password longer than 72 bytes can not be hashed by bcrypt, so it never matches.
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return false, ErrPasswordMismatch
+		}
+		return false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, err
+	}
+
+	return cost != a.cost, nil
+}
@@ -0,0 +1,107 @@
+package crypto_utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams are cheap parameters, so tests do not spend time on hashing.
+var testParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestPasswordHasher(t *testing.T) {
+	h, err := NewPasswordHasher(AlgorithmArgon2id, testParams, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	encoded, err := h.Hash("donkihote")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$"), encoded)
+
+	other, err := h.Hash("donkihote")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other, "salt is random")
+
+	needsRehash, err := h.Verify("donkihote", encoded)
+	require.NoError(t, err)
+	assert.False(t, needsRehash)
+
+	_, err = h.Verify("donkihot", encoded)
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+
+	// passwords longer than 72 bytes are not truncated.
+	long := strings.Repeat("a", 72)
+	encoded, err = h.Hash(long + "b")
+	require.NoError(t, err)
+	_, err = h.Verify(long+"c", encoded)
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+
+	_, err = h.Verify("donkihote", "plain")
+	assert.Error(t, err)
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	old, err := NewPasswordHasher(AlgorithmBcrypt, testParams, bcrypt.MinCost)
+	require.NoError(t, err)
+	bcryptHash, err := old.Hash("donkihote")
+	require.NoError(t, err)
+
+	h, err := NewPasswordHasher(AlgorithmArgon2id, testParams, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	// hashes of other algorithms are verified and need rehash.
+	needsRehash, err := h.Verify("donkihote", bcryptHash)
+	require.NoError(t, err)
+	assert.True(t, needsRehash)
+	_, err = h.Verify("donkihot", bcryptHash)
+	assert.ErrorIs(t, err, ErrPasswordMismatch)
+
+	// hashes of outdated parameters are verified by their own parameters and need rehash.
+	stronger := testParams
+	stronger.Iterations = 2
+	h, err = NewPasswordHasher(AlgorithmArgon2id, stronger, bcrypt.MinCost)
+	require.NoError(t, err)
+	encoded, err := (&argon2idAlgorithm{params: testParams}).hash("donkihote")
+	require.NoError(t, err)
+
+	needsRehash, err = h.Verify("donkihote", encoded)
+	require.NoError(t, err)
+	assert.True(t, needsRehash)
+
+	_, err = NewPasswordHasher("md5", testParams, bcrypt.MinCost)
+	assert.Error(t, err)
+}
+
+func TestNewPasswordHasherParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     Argon2idParams
+		bcryptCost int
+		wantErr    bool
+	}{
+		{name: "valid", params: testParams, bcryptCost: bcrypt.MinCost},
+		{name: "max bcrypt cost", params: testParams, bcryptCost: bcrypt.MaxCost},
+		{name: "zero memory", params: Argon2idParams{Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}, bcryptCost: bcrypt.MinCost, wantErr: true},
+		{name: "zero iterations", params: Argon2idParams{Memory: 64, Parallelism: 1, SaltLength: 16, KeyLength: 32}, bcryptCost: bcrypt.MinCost, wantErr: true},
+		{name: "zero parallelism", params: Argon2idParams{Memory: 64, Iterations: 1, SaltLength: 16, KeyLength: 32}, bcryptCost: bcrypt.MinCost, wantErr: true},
+		{name: "zero salt length", params: Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLength: 32}, bcryptCost: bcrypt.MinCost, wantErr: true},
+		{name: "zero key length", params: Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16}, bcryptCost: bcrypt.MinCost, wantErr: true},
+		{name: "bcrypt cost below min", params: testParams, bcryptCost: bcrypt.MinCost - 1, wantErr: true},
+		{name: "bcrypt cost above max", params: testParams, bcryptCost: bcrypt.MaxCost + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{AlgorithmArgon2id, AlgorithmBcrypt} {
+				_, err := NewPasswordHasher(name, tt.params, tt.bcryptCost)
+				if tt.wantErr {
+					assert.Error(t, err, name)
+				} else {
+					assert.NoError(t, err, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package crypto_utils
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveKey derives a key of size bytes for purpose from a master key by HKDF-SHA256,
+// so a key leaked from one purpose does not reveal keys of other purposes.
+func DeriveKey(master, purpose string, size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(master), nil, []byte(purpose)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Sealer encrypts secrets which must be read back (ex: TOTP secrets) by XChaCha20-Poly1305,
+// unlike passwords and tokens which are only hashed.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer creates a sealer by a key of [chacha20poly1305.KeySize] bytes.
+func NewSealer(key []byte) (*Sealer, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create sealer: %w", err)
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns the base64 of a random nonce followed by the encrypted plaintext.
+func (s *Sealer) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return base64.RawStdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// Open returns the plaintext of sealed which was returned by [Sealer.Seal].
+func (s *Sealer) Open(sealed string) (string, error) {
+	b, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("sealed secret is not valid: %w", err)
+	}
+	if len(b) < s.aead.NonceSize() {
+		return "", fmt.Errorf("sealed secret is not valid")
+	}
+
+	plaintext, err := s.aead.Open(nil, b[:s.aead.NonceSize()], b[s.aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to open sealed secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,82 @@
+package crypto_utils
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors of RFC 6238 for SHA1, truncated to 6 digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for unix, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := TOTPCode(secret, TOTPStep(now.Add(-TOTPPeriod)))
+	require.NoError(t, err)
+
+	step, ok, err := ValidateTOTP(secret, code, now, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	_, ok, err = ValidateTOTP(secret, code, now.Add(TOTPPeriod), 1)
+	require.NoError(t, err)
+	assert.False(t, ok, "code is out of skew")
+
+	_, ok, err = ValidateTOTP(secret, "12345", now, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	u, err := url.Parse(TOTPProvisioningURI("user-management", "dat", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/user-management:dat", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "user-management", u.Query().Get("issuer"))
+}
+
+func TestSealer(t *testing.T) {
+	key, err := DeriveKey("NUWe6IcMRNwLQU1qduIAj7Yntf5mRLnv", "test", 32)
+	require.NoError(t, err)
+	s, err := NewSealer(key)
+	require.NoError(t, err)
+
+	sealed, err := s.Seal("JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	plaintext, err := s.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plaintext)
+
+	// a key of another purpose can not open it.
+	other, err := DeriveKey("NUWe6IcMRNwLQU1qduIAj7Yntf5mRLnv", "other", 32)
+	require.NoError(t, err)
+	o, err := NewSealer(other)
+	require.NoError(t, err)
+	_, err = o.Open(sealed)
+	assert.Error(t, err)
+}
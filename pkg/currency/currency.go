@@ -0,0 +1,104 @@
+// Package currency provides ISO-4217 currency codes and conversion of amounts stored in minor units.
+package currency
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Default is the currency used when an account does not specify one.
+const Default = "JPY"
+
+// exponents is the number of minor unit digits of ISO-4217 currencies.
+// ex: 100 JPY is stored as 100 but 1.00 USD is stored as 100.
+var exponents = map[string]int{
+	"AUD": 2,
+	"BHD": 3,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"NZD": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// Normalize returns the upper case of the code.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValid returns true if the code is a supported ISO-4217 currency.
+func IsValid(code string) bool {
+	_, ok := exponents[code]
+	return ok
+}
+
+// Exponent returns the number of minor unit digits of the currency.
+func Exponent(code string) (int, error) {
+	exp, ok := exponents[code]
+	if !ok {
+		return 0, fmt.Errorf("currency %q is not supported", code)
+	}
+
+	return exp, nil
+}
+
+// ParseRate returns a rational rate from a decimal string like "0.0067".
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("rate %q is not valid", rate)
+	}
+
+	return r, nil
+}
+
+// Convert returns the amount in minor units of "to" currency, converted from minor units of "from" currency
+// where 1 major unit of "from" equals rate major units of "to". The result is rounded half away from zero.
+func Convert(amount int64, from, to string, rate *big.Rat) (int64, error) {
+	fromExp, err := Exponent(from)
+	if err != nil {
+		return 0, err
+	}
+
+	toExp, err := Exponent(to)
+	if err != nil {
+		return 0, err
+	}
+
+	result := new(big.Rat).SetInt64(amount)
+	result.Mul(result, rate)
+	result.Mul(result, new(big.Rat).SetFrac(pow10(toExp), pow10(fromExp)))
+
+	return round(result), nil
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// round returns the nearest integer of r, half away from zero.
+func round(r *big.Rat) int64 {
+	// (2*|num| + denom) / (2*denom) is the rounded absolute value.
+	num := new(big.Int).Abs(r.Num())
+	num.Lsh(num, 1).Add(num, r.Denom())
+	denom := new(big.Int).Lsh(r.Denom(), 1)
+
+	result := new(big.Int).Quo(num, denom)
+	if r.Sign() < 0 {
+		result.Neg(result)
+	}
+
+	return result.Int64()
+}
@@ -0,0 +1,66 @@
+package currency
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		from   string
+		to     string
+		rate   string
+		want   int64
+	}{
+		{
+			name:   "jpy to usd",
+			amount: 15000,
+			from:   "JPY",
+			to:     "USD",
+			rate:   "0.0067",
+			want:   10050, // 100.50 USD
+		},
+		{
+			name:   "usd to jpy",
+			amount: 100, // 1.00 USD
+			from:   "USD",
+			to:     "JPY",
+			rate:   "149.5",
+			want:   150, // 149.5 rounded half away from zero
+		},
+		{
+			name:   "negative amount",
+			amount: -100,
+			from:   "USD",
+			to:     "JPY",
+			rate:   "149.5",
+			want:   -150,
+		},
+		{
+			name:   "same exponent",
+			amount: 1000, // 10.00 EUR
+			from:   "EUR",
+			to:     "USD",
+			rate:   "1.0875",
+			want:   1088,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			require.NoError(t, err)
+
+			got, err := Convert(tt.amount, tt.from, tt.to, rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Convert(100, "XXX", "USD", big.NewRat(1, 1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cursorSeparator = ":"
+
+// EncodeCursor returns an opaque cursor of keyset pagination by the sort time and id of the last item.
+func EncodeCursor(t time.Time, id int64) string {
+	raw := fmt.Sprintf("%d%s%d", t.UnixNano(), cursorSeparator, id)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor returns the sort time and id which was encoded from [EncodeCursor].
+func DecodeCursor(cursor string) (time.Time, int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("cursor is not valid")
+	}
+
+	nanoStr, idStr, ok := strings.Cut(string(b), cursorSeparator)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("cursor is not valid")
+	}
+
+	nano, err := strconv.ParseInt(nanoStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("cursor is not valid")
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("cursor is not valid")
+	}
+
+	return time.Unix(0, nano), id, nil
+}
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	postedAt := time.Date(2023, 11, 20, 10, 30, 0, 123456000, time.UTC)
+	cursor := EncodeCursor(postedAt, 1727000123456)
+
+	gotTime, gotID, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.True(t, postedAt.Equal(gotTime))
+	assert.Equal(t, int64(1727000123456), gotID)
+
+	_, _, err = DecodeCursor("not-a-cursor")
+	assert.Error(t, err)
+}
@@ -6,9 +6,30 @@ import (
 )
 
 // Executor is a presentation of an database executor with exec and query command.
-// Example implementation is [database/sql.Tx] and [database/sql.DB]
+// Example implementation is [database/sql.Tx] and [database/sql.DB] wrapped by [WithTracing].
 type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) Row
+}
+
+// Querier is a presentation of [database/sql.Tx] and [database/sql.DB] which are wrapped into an [Executor].
+type Querier interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
+
+// Rows is a presentation of rows returned by a query, like [database/sql.Rows].
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// Row is a presentation of the single row returned by a query, like [database/sql.Row].
+type Row interface {
+	Scan(dest ...any) error
+	Err() error
+}
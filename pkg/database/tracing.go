@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "user-management/pkg/database"
+
+const (
+	// rowsAffectedKey is the attribute of the number of rows affected by an exec.
+	rowsAffectedKey = attribute.Key("db.rows_affected")
+	// rowsReturnedKey is the attribute of the number of rows read by the caller of a query.
+	rowsReturnedKey = attribute.Key("db.rows_returned")
+)
+
+// tracingExecutor is an [Executor] which records a span per statement by the global tracer provider of otel.
+type tracingExecutor struct {
+	next   Querier
+	tracer trace.Tracer
+}
+
+// WithTracing returns an [Executor] recording a span with the statement for every call of next.
+// Rows affected are recorded for exec, spans of queries end when their rows are closed (or scanned for a single row),
+// so they cover reading rows and record the number of rows returned.
+func WithTracing(next Querier) Executor {
+	return &tracingExecutor{
+		next:   next,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+// ExecContext is implementation of ExecContext by [tracingExecutor] in [Executor]
+func (e *tracingExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := e.start(ctx, query)
+	defer span.End()
+
+	result, err := e.next.ExecContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil {
+		span.SetAttributes(rowsAffectedKey.Int64(rows))
+	}
+
+	return result, nil
+}
+
+// QueryContext is implementation of QueryContext by [tracingExecutor] in [Executor],
+// the span ends when the returned rows are closed or fully read.
+func (e *tracingExecutor) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	ctx, span := e.start(ctx, query)
+
+	rows, err := e.next.QueryContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	return &tracingRows{rows: rows, span: span}, nil
+}
+
+// QueryRowContext is implementation of QueryRowContext by [tracingExecutor] in [Executor],
+// the span ends when the row is scanned.
+func (e *tracingExecutor) QueryRowContext(ctx context.Context, query string, args ...any) Row {
+	rows, err := e.QueryContext(ctx, query, args...)
+
+	return &tracingRow{rows: rows, err: err}
+}
+
+// tracingRows is [Rows] which ends the span of its query when it is closed or fully read.
+type tracingRows struct {
+	rows     *sql.Rows
+	span     trace.Span
+	returned int64
+	ended    bool
+}
+
+func (r *tracingRows) Next() bool {
+	if r.rows.Next() {
+		r.returned++
+		return true
+	}
+
+	// rows are closed by [sql.Rows.Next] after the last row, callers may not close them again.
+	r.end()
+
+	return false
+}
+
+func (r *tracingRows) Scan(dest ...any) error {
+	err := r.rows.Scan(dest...)
+	if err != nil && !r.ended {
+		recordError(r.span, err)
+	}
+
+	return err
+}
+
+func (r *tracingRows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *tracingRows) Close() error {
+	err := r.rows.Close()
+	r.end()
+
+	return err
+}
+
+// end ends the span once with the number of rows returned and the error of iteration if any.
+func (r *tracingRows) end() {
+	if r.ended {
+		return
+	}
+	r.ended = true
+
+	if err := r.rows.Err(); err != nil {
+		recordError(r.span, err)
+	}
+	r.span.SetAttributes(rowsReturnedKey.Int64(r.returned))
+	r.span.End()
+}
+
+// tracingRow is [Row] which reads the first row of rows like [sql.Row].
+type tracingRow struct {
+	rows Rows
+	err  error
+}
+
+func (r *tracingRow) Err() error {
+	return r.err
+}
+
+// Scan copies the first row into dest and closes rows, it returns [sql.ErrNoRows] if there is no row.
+func (r *tracingRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	return r.rows.Close()
+}
+
+// start starts a client span named by the operation of statement (ex: SELECT).
+func (e *tracingExecutor) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	// statements are indented in source, whitespaces are collapsed to keep them readable.
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+
+	return e.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(statement),
+		),
+	)
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
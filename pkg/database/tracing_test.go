@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// countDriver is a sql driver answering "SELECT n" by n rows of a single column and failing other queries.
+type countDriver struct{}
+
+func (countDriver) Open(string) (driver.Conn, error) { return countConn{}, nil }
+
+type countConn struct{}
+
+func (countConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (countConn) Close() error                        { return nil }
+func (countConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (countConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(query, "SELECT "))
+	if err != nil {
+		return nil, errors.New("syntax error")
+	}
+
+	return &countRows{n: n}, nil
+}
+
+type countRows struct {
+	n, i int
+}
+
+func (r *countRows) Columns() []string { return []string{"i"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.i >= r.n {
+		return io.EOF
+	}
+	r.i++
+	dest[0] = int64(r.i)
+
+	return nil
+}
+
+func init() {
+	sql.Register("count", countDriver{})
+}
+
+func newTracingExecutor(t *testing.T) (*tracingExecutor, *tracetest.SpanRecorder) {
+	db, err := sql.Open("count", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return &tracingExecutor{next: db, tracer: provider.Tracer(tracerName)}, recorder
+}
+
+func rowsReturned(span sdktrace.ReadOnlySpan) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == rowsReturnedKey {
+			return attr.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestTracingQueryContext(t *testing.T) {
+	ctx := context.Background()
+	e, recorder := newTracingExecutor(t)
+
+	rows, err := e.QueryContext(ctx, "SELECT 3")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.True(t, rows.Next())
+	assert.Empty(t, recorder.Ended(), "span is still open while reading rows")
+
+	require.NoError(t, rows.Close())
+	require.NoError(t, rows.Close())
+	spans := recorder.Ended()
+	require.Len(t, spans, 1, "span ends once")
+	assert.Equal(t, "SELECT", spans[0].Name())
+	assert.Equal(t, int64(2), rowsReturned(spans[0]).AsInt64())
+
+	// rows fully read end the span even if they are not closed.
+	rows, err = e.QueryContext(ctx, "SELECT 2")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, int64(2), rowsReturned(spans[1]).AsInt64())
+
+	_, err = e.QueryContext(ctx, "SELECT many")
+	require.Error(t, err)
+	spans = recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
+
+func TestTracingQueryRowContext(t *testing.T) {
+	ctx := context.Background()
+	e, recorder := newTracingExecutor(t)
+
+	var i int64
+	row := e.QueryRowContext(ctx, "SELECT 5")
+	require.NoError(t, row.Err())
+	require.NoError(t, row.Scan(&i))
+	assert.Equal(t, int64(1), i)
+
+	err := e.QueryRowContext(ctx, "SELECT 0").Scan(&i)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	row = e.QueryRowContext(ctx, "SELECT many")
+	assert.Error(t, row.Err())
+	assert.Equal(t, row.Err(), row.Scan(&i))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, int64(1), rowsReturned(spans[0]).AsInt64())
+	assert.Equal(t, int64(0), rowsReturned(spans[1]).AsInt64())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
@@ -1,6 +1,9 @@
 package database
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // NullString help to transform string to [database/sql.NullString]
 func NullString(str string) sql.NullString {
@@ -17,3 +20,11 @@ func NullInt64(val int64) sql.NullInt64 {
 
 	return result
 }
+
+// NullTime help to transform time to [database/sql.NullTime]
+func NullTime(val time.Time) sql.NullTime {
+	var result sql.NullTime
+	result.Scan(val)
+
+	return result
+}
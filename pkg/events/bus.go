@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler handles an event published to [Bus].
+type Handler[E any] func(ctx context.Context, event E)
+
+// Bus is an in-process publisher of events of type E, handlers are called synchronously in order of subscribing.
+type Bus[E any] struct {
+	mu       sync.RWMutex
+	handlers []Handler[E]
+}
+
+// NewBus creates a bus without any handler.
+func NewBus[E any]() *Bus[E] {
+	return &Bus[E]{}
+}
+
+// Subscribe adds handler to be called for every published event.
+func (b *Bus[E]) Subscribe(handler Handler[E]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish calls all handlers with event, it returns after all of them returned.
+func (b *Bus[E]) Publish(ctx context.Context, event E) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
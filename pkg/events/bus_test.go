@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus[int]()
+	bus.Publish(ctx, 0)
+
+	var got []int
+	bus.Subscribe(func(_ context.Context, event int) { got = append(got, event) })
+	bus.Subscribe(func(_ context.Context, event int) { got = append(got, -event) })
+	bus.Publish(ctx, 1)
+	bus.Publish(ctx, 2)
+
+	assert.Equal(t, []int{1, -1, 2, -2}, got)
+}
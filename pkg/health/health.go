@@ -0,0 +1,171 @@
+// Package health provides liveness and readiness probes for orchestrators.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"user-management/pkg/processor"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+// CheckResult is the result of a single health check, latency and error are only reported in details.
+type CheckResult struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the json body of probe responses.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name    string
+	checker processor.HealthChecker
+}
+
+// Checker aggregates health checks of factories into readiness.
+type Checker struct {
+	// timeout bounds every check, so a hanging dependency can not block probes.
+	timeout  time.Duration
+	checks   []check
+	draining atomic.Bool
+}
+
+// NewChecker creates a checker running every check with timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Add adds a health check of name, checks must be added before serving probes.
+func (c *Checker) Add(name string, checker processor.HealthChecker) {
+	c.checks = append(c.checks, check{name: name, checker: checker})
+}
+
+// AddFactory adds the health check of f as name if f implements [processor.HealthChecker],
+// so checks are discovered from registered factories.
+func (c *Checker) AddFactory(name string, f processor.Factory) {
+	if checker, ok := f.(processor.HealthChecker); ok {
+		c.Add(name, checker)
+	}
+}
+
+// Drain marks the service as not ready, it is called when graceful shutdown starts
+// so orchestrators stop routing new requests while in-flight requests are drained.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs all checks concurrently and returns the report, the status is ok only if all checks pass.
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, ch := range c.checks {
+		wg.Add(1)
+		go func(ch check) {
+			defer wg.Done()
+			result := c.run(ctx, ch.checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[ch.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(ch)
+	}
+	wg.Wait()
+
+	// checks are still reported while draining, so it is clear that the service is only shutting down.
+	if c.draining.Load() {
+		report.Status = StatusDraining
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, checker processor.HealthChecker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.HealthCheck(ctx)
+	result := CheckResult{
+		Status:  StatusOK,
+		Latency: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LivenessHandler returns a handler responding 200 while the process is able to serve http,
+// dependencies are not checked so a broken dependency does not restart the process.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, http.StatusOK, &Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler returns a handler responding the status of [Checker.Check] with names and statuses of checks,
+// the status code is 503 if a check fails or the service is draining.
+// Errors of dependencies may reveal their addresses, so they are only served by [Checker.DetailedReadinessHandler].
+func (c *Checker) ReadinessHandler() http.Handler {
+	return c.readinessHandler(false)
+}
+
+// DetailedReadinessHandler returns a handler like [Checker.ReadinessHandler] which also responds latencies and errors
+// of checks, it must only be served to operators (ex: by the admin listener).
+func (c *Checker) DetailedReadinessHandler() http.Handler {
+	return c.readinessHandler(true)
+}
+
+func (c *Checker) readinessHandler(detailed bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+
+		code := http.StatusOK
+		if report.Status != StatusOK {
+			code = http.StatusServiceUnavailable
+		}
+
+		if !detailed {
+			for name, result := range report.Checks {
+				report.Checks[name] = CheckResult{Status: result.Status}
+			}
+		}
+		writeReport(w, code, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	// probes must never be cached by proxies.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type checkFunc func(context.Context) error
+
+func (f checkFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+// factory is a factory which checks its health by check.
+type factory struct {
+	checkFunc
+}
+
+func (factory) Connect(context.Context) error { return nil }
+func (factory) Close(context.Context) error   { return nil }
+
+// plainFactory is a factory without health check.
+type plainFactory struct{}
+
+func (plainFactory) Connect(context.Context) error { return nil }
+func (plainFactory) Close(context.Context) error   { return nil }
+
+func readiness(t *testing.T, c *Checker) (int, *Report) {
+	return serveReport(t, c.DetailedReadinessHandler())
+}
+
+func serveReport(t *testing.T, handler http.Handler) (int, *Report) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+	return w.Code, &report
+}
+
+func TestChecker(t *testing.T) {
+	ok := checkFunc(func(context.Context) error { return nil })
+	hanging := checkFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	t.Run("ready if all checks pass", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.Add("postgres", ok)
+
+		code, report := readiness(t, c)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	})
+
+	t.Run("not ready if a check fails or times out", func(t *testing.T) {
+		c := NewChecker(10 * time.Millisecond)
+		c.Add("postgres", ok)
+		c.Add("redis", checkFunc(func(context.Context) error { return errors.New("connection refused") }))
+		c.Add("hanging", hanging)
+
+		code, report := readiness(t, c)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusUnavailable, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+		assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["hanging"].Error)
+	})
+
+	t.Run("public readiness only reports statuses of checks", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.Add("postgres", checkFunc(func(context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") }))
+
+		code, report := serveReport(t, c.ReadinessHandler())
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusUnavailable, report.Status)
+		assert.Equal(t, CheckResult{Status: StatusUnavailable}, report.Checks["postgres"])
+
+		_, report = readiness(t, c)
+		assert.Equal(t, "dial tcp 10.0.0.5:5432: connection refused", report.Checks["postgres"].Error)
+	})
+
+	t.Run("checks are discovered from factories", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.AddFactory("postgres", factory{ok})
+		c.AddFactory("tracing", plainFactory{})
+
+		_, report := readiness(t, c)
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Len(t, report.Checks, 1)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	})
+
+	t.Run("not ready while draining but still alive", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.Add("postgres", ok)
+		c.Drain()
+
+		code, report := readiness(t, c)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusDraining, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+
+		w := httptest.NewRecorder()
+		c.LivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
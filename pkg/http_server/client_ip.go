@@ -0,0 +1,69 @@
+package http_server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/logger"
+)
+
+// ForwardedForHeader is the header appended by proxies with the address of their clients.
+const ForwardedForHeader = "X-Forwarded-For"
+
+// clientIPMiddleware represents option that resolves the ip of client from the remote address or trusted proxies.
+type clientIPMiddleware struct {
+	trustedProxyHops int
+}
+
+func (m *clientIPMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, m.trustedProxyHops)
+
+		ctx := xcontext.ImportClientIPToContext(r.Context(), ip)
+		ctx = logger.NewContext(ctx, logger.With(logger.FromContext(ctx), "client_ip", ip))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithClientIP returns a middleware that resolves the ip of client, it is available by [xcontext.ExtractClientIPFromContext].
+// trustedProxyHops is the number of proxies in front of the server appending to "X-Forwarded-For",
+// the client ip is the entry appended by the farthest of them because entries before it are sent by the client.
+// Zero trusts no proxy and uses the remote address of connection.
+// It should be placed after [WithRequestID], so the client ip is added to the logger including the request id.
+func WithClientIP(trustedProxyHops int) Middleware {
+	return &clientIPMiddleware{
+		trustedProxyHops: trustedProxyHops,
+	}
+}
+
+func clientIP(r *http.Request, trustedProxyHops int) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if trustedProxyHops <= 0 {
+		return remote
+	}
+
+	var forwarded []string
+	for _, value := range r.Header.Values(ForwardedForHeader) {
+		for _, ip := range strings.Split(value, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(ip))
+		}
+	}
+
+	// the request has not passed all proxies, so its header can not be trusted.
+	if len(forwarded) < trustedProxyHops {
+		return remote
+	}
+
+	ip := forwarded[len(forwarded)-trustedProxyHops]
+	if net.ParseIP(ip) == nil {
+		return remote
+	}
+
+	return ip
+}
@@ -0,0 +1,59 @@
+package http_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_clientIP(t *testing.T) {
+	testCases := []struct {
+		name      string
+		forwarded []string
+		hops      int
+		want      string
+	}{
+		{
+			name:      "remote address if no proxy is trusted",
+			forwarded: []string{"1.1.1.1"},
+			want:      "192.0.2.1",
+		},
+		{
+			name:      "entry appended by the trusted proxy",
+			forwarded: []string{"6.6.6.6, 1.1.1.1"},
+			hops:      1,
+			want:      "1.1.1.1",
+		},
+		{
+			name:      "entry appended by the farthest of trusted proxies",
+			forwarded: []string{"6.6.6.6, 1.1.1.1", "10.0.0.1"},
+			hops:      2,
+			want:      "1.1.1.1",
+		},
+		{
+			name:      "remote address if the request has not passed all proxies",
+			forwarded: []string{"1.1.1.1"},
+			hops:      2,
+			want:      "192.0.2.1",
+		},
+		{
+			name:      "remote address if the entry is not an ip",
+			forwarded: []string{"unknown"},
+			hops:      1,
+			want:      "192.0.2.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+			for _, value := range tc.forwarded {
+				req.Header.Add(ForwardedForHeader, value)
+			}
+
+			assert.Equal(t, tc.want, clientIP(req, tc.hops))
+		})
+	}
+}
@@ -1,19 +1,13 @@
 package http_server
 
-import (
-	"path/filepath"
-	"regexp"
-)
-
 const (
-	slash        = string(filepath.Separator)
-	space        = " "
-	openBracket  = "{"
-	closeBracket = "}"
+	slash          = "/"
+	space          = " "
+	openBracket    = "{"
+	closeBracket   = "}"
+	catchAllSuffix = "..."
 )
 
-var bracketRegex = regexp.MustCompile(`\{(.*?)\}`)
-
 type (
-	wildcardParamsKey struct{}
+	routeKeyCtx struct{}
 )
@@ -4,6 +4,7 @@ package http_server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,6 +16,8 @@ import (
 	"user-management/configs"
 	"user-management/pkg/logger"
 	"user-management/pkg/reflect_utils"
+	"user-management/pkg/validator"
+	"user-management/pkg/xerrors"
 )
 
 // handler is a presentation for a implementation of a delivery API.
@@ -28,7 +31,7 @@ type httpHandler func(http.ResponseWriter, *http.Request)
 type HttpServer struct {
 	logger      logger.Logger
 	endpoint    *configs.Endpoint
-	handlerMap  map[string]httpHandler
+	router      *router
 	server      *http.Server
 	middlewares []Middleware
 }
@@ -39,52 +42,86 @@ func NewHttpServer(
 	logger logger.Logger,
 	middlewares ...Middleware,
 ) *HttpServer {
-	return &HttpServer{
+	s := &HttpServer{
 		logger:      logger,
 		endpoint:    endpoint,
-		handlerMap:  make(map[string]httpHandler),
+		router:      newRouter(),
 		middlewares: middlewares,
 	}
+
+	// the server is created here instead of Start, so Stop never races with Start,
+	// routes registered later are still served because the router is shared.
+	s.server = &http.Server{
+		Handler: s.handler(),
+	}
+	if endpoint != nil {
+		s.server.Addr = endpoint.Address()
+	}
+
+	return s
 }
 
-// Start will start server and matching with processors pattern
+// Start will start server and matching with processors pattern.
+// It returns immediately if Stop was called before.
 func (s *HttpServer) Start(ctx context.Context) error {
-	mux := http.NewServeMux()
-	mux.HandleFunc(slash, func(w http.ResponseWriter, r *http.Request) {
-		for route, next := range s.handlerMap {
-			method, path, _ := strings.Cut(route, space)
-			// checking path and method is matching with route
-			if isMatchPath(path, r.URL.Path) && method == r.Method {
-				next(w, appendWildCardParams(path, r))
-				return
-			}
-		}
-		errorResponse(w, http.StatusNotFound, fmt.Errorf("not found"))
-	})
+	s.logger.Info("server listening in", "address", s.server.Addr)
+	// [http.ErrServerClosed] is returned after Stop, it is not a failure.
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
 
-	var handler http.Handler = mux
-	slices.Reverse(s.middlewares)
+	return nil
+}
+
+// handler returns the handler of all routes wrapped by middlewares.
+func (s *HttpServer) handler() http.Handler {
+	var handler http.Handler = http.HandlerFunc(s.serveRoute)
+	// routing is always the first step, so all middlewares can share the matched route.
+	middlewares := append([]Middleware{&routingMiddleware{router: s.router}}, s.middlewares...)
+	slices.Reverse(middlewares)
 
 	// Merge all middleware handlers into one that can using for register to http server.
-	for _, middleware := range s.middlewares {
+	for _, middleware := range middlewares {
 		handler = middleware.Wrap(handler)
 	}
 
-	s.logger.Info("server listening in", "address", s.endpoint.Address())
-	if err := http.ListenAndServe(s.endpoint.Address(), handler); err != nil {
-		return err
-	}
+	return handler
+}
 
-	return nil
+// serveRoute calls the handler of route which was matched by [routingMiddleware].
+// It responds 405 with "Allow" header if only the path matches, and answers OPTIONS automatically.
+func (s *HttpServer) serveRoute(w http.ResponseWriter, r *http.Request) {
+	result := routeFromContext(r.Context())
+	switch {
+	case result == nil:
+		errorResponse(r.Context(), w, xerrors.NotFound("not found"))
+	case result.route != nil:
+		result.route.handler(w, r)
+	case len(result.allow) > 0:
+		w.Header().Set("Allow", strings.Join(result.allow, ", "))
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeErrorResponse(r.Context(), w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Errorf("method %s is not allowed", r.Method))
+	default:
+		errorResponse(r.Context(), w, xerrors.NotFound("not found"))
+	}
 }
 
-// Stop will stop server with graceful shutdown and matching with processors pattern
+// Stop will stop server with graceful shutdown and matching with processors pattern.
+// It stops accepting new connections and waits for in-flight requests until ctx is done.
 func (s *HttpServer) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
-// Register will register to http server by method, path and handler with generic handler
+// Register will register to http server by method, path and handler with generic handler.
+// Validate tags of Request are checked here, so a malformed tag fails at startup instead of inside a request.
 func Register[Request, Response any](s *HttpServer, method, path string, handler handler[Request, Response]) {
+	if err := validator.Check(new(Request)); err != nil {
+		log.Fatalf("invalid request of %s %s: %v", method, path, err)
+	}
+
 	switch method {
 	case http.MethodOptions:
 	case
@@ -92,7 +129,22 @@ func Register[Request, Response any](s *HttpServer, method, path string, handler
 		http.MethodDelete,
 		http.MethodPost,
 		http.MethodPut:
-		s.handlerMap[joinPath(method, path)] = handleRequest(handler)
+		s.router.add(method, path, handleRequest(handler))
+	default:
+		log.Fatalf("unsupported method %s for http server", method)
+	}
+}
+
+// RegisterHandler registers a plain [net/http.Handler] for method and path, it is used to serve responses
+// which are not json (ex: metrics). Middlewares of server are applied like handlers registered by [Register].
+func RegisterHandler(s *HttpServer, method, path string, handler http.Handler) {
+	switch method {
+	case
+		http.MethodGet,
+		http.MethodDelete,
+		http.MethodPost,
+		http.MethodPut:
+		s.router.add(method, path, handler.ServeHTTP)
 	default:
 		log.Fatalf("unsupported method %s for http server", method)
 	}
@@ -105,24 +157,30 @@ func handleRequest[Request, Response any](handler handler[Request, Response]) ht
 		ctx := r.Context()
 		params, err := retrieveDataFromRequest(w, r)
 		if err != nil {
-			errorResponse(w, http.StatusBadRequest, err)
+			errorResponse(ctx, w, err)
 			return
 		}
 
 		var req Request
 		// convert all params into request struct
 		if err := reflect_utils.ConvertMapToStruct(params, &req); err != nil {
-			errorResponse(w, http.StatusInternalServerError, err)
+			errorResponse(ctx, w, xerrors.InvalidArgument("request is not valid: %w", err))
+			return
+		}
+
+		// validate request by "validate" tags, all violations are returned at once.
+		if violations := validator.Validate(&req); len(violations) > 0 {
+			errorResponse(ctx, w, xerrors.InvalidArgument("request is not valid").WithDetails(violations...))
 			return
 		}
 
 		resp, err := handler(ctx, &req)
 		if err != nil {
-			errorResponse(w, http.StatusBadRequest, err)
+			errorResponse(ctx, w, err)
 			return
 		}
 
-		dataResponse(w, resp)
+		dataResponse(ctx, w, resp)
 	}
 }
 
@@ -134,24 +192,24 @@ func retrieveDataFromRequest(w http.ResponseWriter, r *http.Request) (map[string
 	// retrieve data from request body with Post, Put methods
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return nil, err
+		return nil, xerrors.InvalidArgument("unable to read request body: %w", err)
 	}
 
 	if len(body) > 0 {
 		bodyMap := make(map[string]any)
 		if err := json.Unmarshal(body, &bodyMap); err != nil {
-			return nil, err
+			return nil, xerrors.InvalidArgument("request body is not valid json: %w", err)
 		}
 		maps.Copy(params, bodyMap)
 	}
 
 	// retrieve data from wildcard params (ex: with "/users/{id}" we will got the value of id )
-	wildcardParams, ok := ctx.Value(&wildcardParamsKey{}).(map[string]any)
-	if !ok {
+	result := routeFromContext(ctx)
+	if result == nil {
 		return nil, fmt.Errorf("unable to get wildcard params")
 	}
 
-	maps.Copy(params, wildcardParams)
+	maps.Copy(params, result.params)
 
 	// retrieve data from queries params (ex: with /users?name=dat we will got value of name)
 	for k, v := range r.URL.Query() {
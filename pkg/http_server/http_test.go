@@ -4,15 +4,37 @@ package http_server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"maps"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"user-management/configs"
+	"user-management/pkg/xerrors"
 
 	"github.com/stretchr/testify/require"
 )
 
+// routed returns the request which was routed by a router that only has the pattern.
+func routed(pattern string, r *http.Request) *http.Request {
+	rt := newRouter()
+	rt.add(r.Method, pattern, nil)
+
+	var result *http.Request
+	(&routingMiddleware{router: rt}).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		result = r
+	})).ServeHTTP(nil, r)
+
+	return result
+}
+
 func Test_retrieveDataFromRequest(t *testing.T) {
 	mockBody := struct {
 		Name string `json:"name"`
@@ -30,7 +52,7 @@ func Test_retrieveDataFromRequest(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
 	resp := httptest.NewRecorder()
 
-	params, err := retrieveDataFromRequest(resp, appendWildCardParams(pattern, req))
+	params, err := retrieveDataFromRequest(resp, routed(pattern, req))
 	require.NoError(t, err)
 
 	expectedParams := map[string]any{
@@ -43,3 +65,110 @@ func Test_retrieveDataFromRequest(t *testing.T) {
 
 	require.True(t, maps.Equal(params, expectedParams))
 }
+
+func Test_errorResponse(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantReason string
+		wantMsg    string
+		wantRetry  string
+	}{
+		{
+			name:       "not found with reason",
+			err:        fmt.Errorf("unable to retrieve user by id: %w", xerrors.NotFound("user does not exists").WithReason("USER_NOT_FOUND")),
+			wantCode:   http.StatusNotFound,
+			wantReason: "USER_NOT_FOUND",
+			wantMsg:    "unable to retrieve user by id: user does not exists",
+		},
+		{
+			name:       "conflict",
+			err:        xerrors.Conflict("username already exists"),
+			wantCode:   http.StatusConflict,
+			wantReason: "CONFLICT",
+			wantMsg:    "username already exists",
+		},
+		{
+			name:       "untyped error is internal and hidden",
+			err:        errors.New("dial tcp: connection refused"),
+			wantCode:   http.StatusInternalServerError,
+			wantReason: "INTERNAL",
+			wantMsg:    "there was an internal server error",
+		},
+		{
+			name:       "too many requests with retry after rounded up",
+			err:        xerrors.TooManyRequests("too many failed login attempts").WithRetryAfter(1500 * time.Millisecond),
+			wantCode:   http.StatusTooManyRequests,
+			wantReason: "TOO_MANY_REQUESTS",
+			wantMsg:    "too many failed login attempts",
+			wantRetry:  "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			errorResponse(context.Background(), w, tc.err)
+
+			var resp response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Equal(t, tc.wantCode, resp.Code)
+			require.Equal(t, tc.wantReason, resp.Reason)
+			require.Equal(t, tc.wantMsg, resp.Message)
+			require.Equal(t, tc.wantRetry, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func Test_handleRequestValidation(t *testing.T) {
+	type request struct {
+		ID       int64  `json:"id" validate:"required"`
+		UserName string `json:"user_name" validate:"required,max=5"`
+		Amount   int64  `json:"amount" validate:"gt=0"`
+	}
+	called := false
+	h := handleRequest(func(ctx context.Context, req *request) (*struct{}, error) {
+		called = true
+		return &struct{}{}, nil
+	})
+
+	b, err := json.Marshal(map[string]any{"user_name": "duyledat", "amount": -1})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/users/0", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	h(w, routed("/users/{id}", req))
+
+	var resp response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.False(t, called)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, []string{
+		"id must not be empty",
+		"user_name must be at most 5 characters",
+		"amount must be greater than 0",
+	}, resp.Details)
+}
+
+func TestHttpServerStopRightAfterStart(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		s := NewHttpServer(&configs.Endpoint{Host: "127.0.0.1", Port: "0"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- s.Start(context.Background())
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		require.NoError(t, s.Stop(ctx))
+		cancel()
+
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("server is still serving after stop")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package http_server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/logger"
+)
+
+// RequestIDHeader is the header carrying the id of request from client and back to client.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds ids from clients, so they can not flood logs.
+const maxRequestIDLength = 128
+
+// requestIDMiddleware represents option that assigns an id to every request and a logger including the id.
+type requestIDMiddleware struct {
+	logger logger.Logger
+}
+
+func (m *requestIDMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := xcontext.ImportRequestIDToContext(r.Context(), id)
+		ctx = logger.NewContext(ctx, logger.With(m.logger, "request_id", id))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithRequestID returns a middleware that honours the "X-Request-ID" header of request or generates a new id,
+// the id is sent back in response and l with the id is available by [logger.FromContext].
+// It should be the first middleware, so logs of other middlewares include the id.
+func WithRequestID(l logger.Logger) Middleware {
+	return &requestIDMiddleware{
+		logger: l,
+	}
+}
+
+// validRequestID reports whether id from client is safe to be logged and sent back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// reading random bytes never fails on supported platforms.
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// accessLogMiddleware represents option that logs a line per request by the logger of request context.
+type accessLogMiddleware struct{}
+
+func (m *accessLogMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := RoutePattern(r.Context())
+		if route == "" {
+			route = unmatchedRoute
+		}
+		args := []any{
+			"method", r.Method,
+			"route", route,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"latency", time.Since(start),
+		}
+
+		l := logger.FromContext(r.Context())
+		if recorder.status >= http.StatusInternalServerError {
+			l.Error("http request", args...)
+			return
+		}
+		l.Info("http request", args...)
+	})
+}
+
+// WithAccessLog returns a middleware that logs method, route pattern, status, bytes and latency of every request.
+// It should be placed after [WithRequestID], so access logs include the request id.
+func WithAccessLog() Middleware {
+	return &accessLogMiddleware{}
+}
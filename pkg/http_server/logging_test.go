@@ -0,0 +1,109 @@
+package http_server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestIDAndAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	s := NewHttpServer(nil, nil, WithRequestID(l), WithAccessLog())
+	var handlerID string
+	Register(s, http.MethodGet, "/users/{id}", func(ctx context.Context, req *struct {
+		ID int64 `json:"id"`
+	}) (*struct{}, error) {
+		handlerID = xcontext.ExtractRequestIDFromContext(ctx)
+		logger.FromContext(ctx).Info("handled")
+		return &struct{}{}, nil
+	})
+	handler := s.handler()
+
+	t.Run("honours id of client", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set(RequestIDHeader, "client-id.1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "client-id.1", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "client-id.1", handlerID)
+
+		logs := decodeLogs(t, &buf)
+		require.Len(t, logs, 2)
+		assert.Equal(t, "handled", logs[0]["msg"])
+		assert.Equal(t, "client-id.1", logs[0]["request_id"])
+
+		assert.Equal(t, "http request", logs[1]["msg"])
+		assert.Equal(t, "client-id.1", logs[1]["request_id"])
+		assert.Equal(t, "/users/{id}", logs[1]["route"])
+		assert.Equal(t, float64(http.StatusOK), logs[1]["status"])
+		assert.Equal(t, float64(w.Body.Len()), logs[1]["bytes"])
+	})
+
+	t.Run("replaces invalid id of client", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		req.Header.Set(RequestIDHeader, "bad id\n")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		assert.Len(t, id, 32)
+
+		logs := decodeLogs(t, &buf)
+		require.Len(t, logs, 1)
+		assert.Equal(t, id, logs[0]["request_id"])
+		assert.Equal(t, unmatchedRoute, logs[0]["route"])
+		assert.Equal(t, float64(http.StatusNotFound), logs[0]["status"])
+	})
+}
+
+func TestWithRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	s := NewHttpServer(nil, nil, WithRequestID(l), WithRecovery())
+	Register(s, http.MethodGet, "/panic", func(ctx context.Context, req *struct{}) (*struct{}, error) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(RequestIDHeader, "panic-id")
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "boom")
+
+	logs := decodeLogs(t, &buf)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "panic recovered", logs[0]["msg"])
+	assert.Equal(t, "panic-id", logs[0]["request_id"])
+	assert.Equal(t, "boom", logs[0]["panic"])
+	assert.Contains(t, logs[0]["stack"], "TestWithRecovery")
+}
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var logs []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var m map[string]any
+		require.NoError(t, dec.Decode(&m))
+		logs = append(logs, m)
+	}
+
+	return logs
+}
@@ -0,0 +1,92 @@
+package http_server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute is the route label of requests which do not match any route,
+// raw paths are never used as label so the number of series is bounded.
+const unmatchedRoute = "unmatched"
+
+// metricsMiddleware represents option that records count and latency of requests by route pattern and status.
+type metricsMiddleware struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func (m *metricsMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := RoutePattern(r.Context())
+		if route == "" {
+			route = unmatchedRoute
+		}
+		labels := prometheus.Labels{
+			"method": r.Method,
+			"route":  route,
+			"status": strconv.Itoa(recorder.status),
+		}
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// WithMetrics returns a middleware that registers and records http_requests_total and
+// http_request_duration_seconds labeled by method, route pattern and status to registerer.
+// It should be the first middleware, so responses of other middlewares are recorded too.
+func WithMetrics(registerer prometheus.Registerer) Middleware {
+	labels := []string{"method", "route", "status"}
+	m := &metricsMiddleware{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of handled http requests.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of handled http requests.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+	registerer.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// statusRecorder is a [net/http.ResponseWriter] which remembers the written status code and size of body.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	// only the first status is sent to client.
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	// writing body without status sends 200 to client.
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+
+	return n, err
+}
+
+// Unwrap returns the original writer, so [net/http.ResponseController] can reach it.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,38 @@
+package http_server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-management/pkg/xerrors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s := NewHttpServer(nil, nil, WithMetrics(registry))
+	Register(s, http.MethodGet, "/users/{id}", func(_ context.Context, req *struct {
+		ID int64 `json:"id"`
+	}) (*struct{}, error) {
+		if req.ID == 0 {
+			return nil, xerrors.NotFound("user does not exists")
+		}
+		return &struct{}{}, nil
+	})
+	handler := s.handler()
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	m := s.middlewares[0].(*metricsMiddleware)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/users/{id}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/users/{id}", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.duration))
+}
@@ -1,15 +1,19 @@
 package http_server
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"runtime/debug"
 	"slices"
 	"strings"
 	"user-management/internal/entities"
 	"user-management/pkg/http_server/xcontext"
 	"user-management/pkg/logger"
 	"user-management/pkg/token_utils"
+	"user-management/pkg/xerrors"
 )
 
 // Middleware represents options that can be used to configure http server
@@ -33,17 +37,26 @@ type corsMiddleware struct {
 }
 
 // Wrap is an implementation of [corsOptions] to wrap next handler into cors handler.
+// Only preflight requests are answered here, other OPTIONS requests are answered by the router with their Allow header.
 func (m *corsMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", strings.Join(m.allowMethods, ", "))
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, authorization")
-		if r.Method != http.MethodOptions {
-			next.ServeHTTP(w, r)
+		if isPreflight(r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
 		}
+
+		next.ServeHTTP(w, r)
 	})
 }
 
+// isPreflight reports whether r is a cors preflight request, which is an OPTIONS request asking for a method.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
 func WithCors(methods ...string) Middleware {
 	var allowMethods []string
 	if len(methods) == 0 {
@@ -54,23 +67,15 @@ func WithCors(methods ...string) Middleware {
 	}
 }
 
-// corsMiddleware represents option that implements rbac for authorized.
+// rbacMiddleware represents option that implements rbac for authorized.
 type rbacMiddleware struct {
+	// rbacMap is keyed by [routeKey], so it shares the same route table with server.
 	rbacMap map[string][]entities.User_Role
 }
 
 func (m *rbacMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var validRoles []entities.User_Role
-
-		for route, roles := range m.rbacMap {
-			method, path, _ := strings.Cut(route, space)
-			// checking path and method is matching with route
-			if isMatchPath(path, r.URL.Path) && method == r.Method {
-				validRoles = roles
-				break
-			}
-		}
+		validRoles := m.rbacMap[matchedRouteKey(r.Context())]
 
 		if len(validRoles) == 0 {
 			next.ServeHTTP(w, r)
@@ -79,12 +84,12 @@ func (m *rbacMiddleware) Wrap(next http.Handler) http.Handler {
 
 		info, err := xcontext.ExtractUserInfoFromContext(r.Context())
 		if err != nil {
-			errorResponse(w, http.StatusUnauthorized, fmt.Errorf("authorization is not valid: user info not valid"))
+			errorResponse(r.Context(), w, xerrors.Unauthenticated("authorization is not valid: user info not valid"))
 			return
 		}
 
 		if !slices.Contains(validRoles, entities.User_Role(info.Role)) {
-			errorResponse(w, http.StatusForbidden, fmt.Errorf("authorization is not valid: role is not valid"))
+			errorResponse(r.Context(), w, xerrors.PermissionDenied("authorization is not valid: role is not valid"))
 			return
 		}
 
@@ -93,75 +98,189 @@ func (m *rbacMiddleware) Wrap(next http.Handler) http.Handler {
 }
 
 func WithRBAC(rbacMap map[string][]entities.User_Role) Middleware {
+	m := make(map[string][]entities.User_Role, len(rbacMap))
+	for route, roles := range rbacMap {
+		m[routeKey(parseRoute(route))] = roles
+	}
+
 	return &rbacMiddleware{
-		rbacMap: rbacMap,
+		rbacMap: m,
 	}
 }
 
+// RevocationChecker checks whether a verified token was revoked before its expiration.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, info *xcontext.UserInfo) (bool, error)
+}
+
 // authenticateMiddleware represents options that implements authenticate for a request.
 type authenticateMiddleware struct {
-	tokenGenerator token_utils.Authenticator[*xcontext.UserInfo]
-	ignoreRoutes   []string
+	tokenGenerator    token_utils.Authenticator[*xcontext.UserInfo]
+	revocationChecker RevocationChecker
+	// ignoreRoutes is a set of [routeKey], so it shares the same route table with server.
+	ignoreRoutes map[string]struct{}
 }
 
 func (m *authenticateMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		for _, route := range m.ignoreRoutes {
-			method, path, _ := strings.Cut(route, space)
-			// checking path and method is matching with route
-			if isMatchPath(path, r.URL.Path) && method == r.Method {
-				next.ServeHTTP(w, r)
-				return
-			}
+		key := matchedRouteKey(r.Context())
+		// unmatched requests will be responded as not found or method not allowed by server.
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if _, ok := m.ignoreRoutes[key]; ok {
+			next.ServeHTTP(w, r)
+			return
 		}
 
 		schema, tkn, ok := strings.Cut(r.Header.Get("Authorization"), space)
 		if !ok || strings.ToLower(schema) != "bearer" {
-			errorResponse(w, http.StatusForbidden, fmt.Errorf("authorization is not valid: schema must be bearer"))
+			errorResponse(r.Context(), w, xerrors.Unauthenticated("authorization is not valid: schema must be bearer"))
 			return
 		}
 		payload, err := m.tokenGenerator.Verify(tkn)
 		if err != nil {
-			errorResponse(w, http.StatusForbidden, err)
+			errorResponse(r.Context(), w, xerrors.Unauthenticated("%w", err))
 			return
 		}
 
-		log.Println(*payload)
+		if m.revocationChecker != nil {
+			revoked, err := m.revocationChecker.IsRevoked(r.Context(), payload)
+			if err != nil {
+				errorResponse(r.Context(), w, err)
+				return
+			}
+
+			if revoked {
+				errorResponse(r.Context(), w, xerrors.Unauthenticated("token is not valid: token has been revoked").WithReason("TOKEN_REVOKED"))
+				return
+			}
+		}
 
-		next.ServeHTTP(w, r.WithContext(xcontext.ImportUserInfoToContext(r.Context(), payload)))
+		ctx := xcontext.ImportUserInfoToContext(r.Context(), payload)
+		// logs after authentication are correlated with the user.
+		ctx = logger.NewContext(ctx, logger.With(logger.FromContext(ctx), "user_id", payload.UserID))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func WithAuthenticate(tokenGenerator token_utils.Authenticator[*xcontext.UserInfo], ignoreRoutes []string) Middleware {
+// WithAuthenticate returns a middleware that verifies bearer token of requests except ignoreRoutes,
+// revocationChecker is optional and used to reject revoked tokens.
+func WithAuthenticate(
+	tokenGenerator token_utils.Authenticator[*xcontext.UserInfo],
+	revocationChecker RevocationChecker,
+	ignoreRoutes []string,
+) Middleware {
+	m := make(map[string]struct{}, len(ignoreRoutes))
+	for _, route := range ignoreRoutes {
+		m[routeKey(parseRoute(route))] = struct{}{}
+	}
+
 	return &authenticateMiddleware{
-		tokenGenerator: tokenGenerator,
-		ignoreRoutes:   ignoreRoutes,
+		tokenGenerator:    tokenGenerator,
+		revocationChecker: revocationChecker,
+		ignoreRoutes:      m,
 	}
 }
 
-// recoveryMiddleware represents options that implements recovery a panic occurs in handle flow for a request.
-type recoveryMiddleware struct {
-	logger logger.Logger
+// AdminKeyHeader is the header carrying the static key of admin listener.
+const AdminKeyHeader = "X-Admin-Key"
+
+// adminMiddleware represents option that only allows super admins or holders of a static key.
+type adminMiddleware struct {
+	key          string
+	authenticate *authenticateMiddleware
+	// ignoreRoutes is a set of [routeKey], so it shares the same route table with server.
+	ignoreRoutes map[string]struct{}
 }
 
+func (m *adminMiddleware) Wrap(next http.Handler) http.Handler {
+	requireSuperAdmin := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, err := xcontext.ExtractUserInfoFromContext(r.Context())
+		if err != nil {
+			errorResponse(r.Context(), w, err)
+			return
+		}
+
+		if entities.User_Role(info.Role) != entities.SuperAdminRole {
+			errorResponse(r.Context(), w, xerrors.PermissionDenied("authorization is not valid: role is not valid"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+	authenticated := m.authenticate.Wrap(requireSuperAdmin)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := matchedRouteKey(r.Context())
+		// unmatched requests will be responded as not found or method not allowed by server.
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if _, ok := m.ignoreRoutes[key]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the key is compared in constant time, so it can not be guessed by timing.
+		if provided := r.Header.Get(AdminKeyHeader); m.key != "" && provided != "" &&
+			subtle.ConstantTimeCompare([]byte(provided), []byte(m.key)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// WithAdmin returns a middleware that only allows requests with a bearer token of super admin or
+// the static key in "X-Admin-Key" header (disabled if key is empty) except ignoreRoutes.
+func WithAdmin(
+	tokenGenerator token_utils.Authenticator[*xcontext.UserInfo],
+	revocationChecker RevocationChecker,
+	key string,
+	ignoreRoutes []string,
+) Middleware {
+	m := make(map[string]struct{}, len(ignoreRoutes))
+	for _, route := range ignoreRoutes {
+		m[routeKey(parseRoute(route))] = struct{}{}
+	}
+
+	return &adminMiddleware{
+		key: key,
+		authenticate: &authenticateMiddleware{
+			tokenGenerator:    tokenGenerator,
+			revocationChecker: revocationChecker,
+		},
+		ignoreRoutes: m,
+	}
+}
+
+// recoveryMiddleware represents options that implements recovery a panic occurs in handle flow for a request.
+type recoveryMiddleware struct{}
+
 func (m *recoveryMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			err := recover()
 			if err != nil {
-
-				m.logger.Error("http handle was got an error", "err", err) // May be log this error? Send to sentry?
-				errorResponse(w, http.StatusInternalServerError, fmt.Errorf("there was an internal server error"))
+				// the logger of request includes the request id, so the stack can be found by the id of a failed request.
+				logger.FromContext(r.Context()).Error("panic recovered", "panic", fmt.Sprint(err), "stack", string(debug.Stack()))
+				writeErrorResponse(r.Context(), w, http.StatusInternalServerError, xerrors.KindInternal.String(), errors.New("there was an internal server error"))
 			}
-
 		}()
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func WithRecovery(logger logger.Logger) Middleware {
-	return &recoveryMiddleware{
-		logger: logger,
-	}
+// WithRecovery returns a middleware that responds 500 for a panic of handle flow,
+// the panic and its stack are logged by the logger of request context.
+func WithRecovery() Middleware {
+	return &recoveryMiddleware{}
 }
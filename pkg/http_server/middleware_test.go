@@ -0,0 +1,84 @@
+package http_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"user-management/internal/entities"
+	"user-management/pkg/http_server/xcontext"
+	"user-management/pkg/token_utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAdmin(t *testing.T) {
+	tokenGenerator, err := token_utils.NewPasetoAuthenticator[*xcontext.UserInfo]("NUWe6IcMRNwLQU1qduIAj7Yntf5mRLnv")
+	require.NoError(t, err)
+	token := func(role entities.User_Role) string {
+		tkn, err := tokenGenerator.Generate(&xcontext.UserInfo{UserID: 1, Role: string(role)}, time.Minute)
+		require.NoError(t, err)
+		return "Bearer " + tkn
+	}
+
+	s := NewHttpServer(nil, nil, WithAdmin(tokenGenerator, nil, "admin-key", []string{"GET /metrics"}))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	RegisterHandler(s, http.MethodGet, "/metrics", ok)
+	RegisterHandler(s, http.MethodGet, "/admin/caches", ok)
+	handler := s.handler()
+
+	testCases := []struct {
+		name     string
+		path     string
+		header   string
+		value    string
+		wantCode int
+	}{
+		{name: "ignored route", path: "/metrics", wantCode: http.StatusOK},
+		{name: "no credential", path: "/admin/caches", wantCode: http.StatusUnauthorized},
+		{name: "static key", path: "/admin/caches", header: AdminKeyHeader, value: "admin-key", wantCode: http.StatusOK},
+		{name: "wrong static key", path: "/admin/caches", header: AdminKeyHeader, value: "admin", wantCode: http.StatusUnauthorized},
+		{name: "super admin token", path: "/admin/caches", header: "Authorization", value: token(entities.SuperAdminRole), wantCode: http.StatusOK},
+		{name: "admin token", path: "/admin/caches", header: "Authorization", value: token(entities.AdminRole), wantCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+		})
+	}
+}
+
+func TestWithCors(t *testing.T) {
+	s := NewHttpServer(nil, nil, WithCors())
+	RegisterHandler(s, http.MethodGet, "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	handler := s.handler()
+
+	// a preflight request is answered by cors.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Allow"))
+
+	// other OPTIONS requests reach the router.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/accounts/1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
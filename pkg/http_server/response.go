@@ -1,54 +1,107 @@
 package http_server
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"user-management/pkg/logger"
+	"user-management/pkg/xerrors"
 )
 
 // response struct present response format to http client.
 type response struct {
 	Code    int      `json:"code"`
+	Reason  string   `json:"reason,omitempty"`
 	Message string   `json:"message,omitempty"`
 	Details []string `json:"details,omitempty"`
 	Data    any      `json:"data,omitempty"`
 }
 
-// errorResponse write error to http response with passing code and error.
-func errorResponse(w http.ResponseWriter, code int, err error) {
+// statusFromError returns the http status code mapped from the kind of error.
+// Untyped errors are treated as internal errors.
+func statusFromError(err error) int {
+	switch xerrors.KindOf(err) {
+	case xerrors.KindInvalidArgument:
+		return http.StatusBadRequest
+	case xerrors.KindNotFound:
+		return http.StatusNotFound
+	case xerrors.KindConflict:
+		return http.StatusConflict
+	case xerrors.KindPermissionDenied:
+		return http.StatusForbidden
+	case xerrors.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case xerrors.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorResponse write error to http response with the status code and reason mapped from error.
+// The message of internal errors is hidden from client and logged by the logger of ctx.
+func errorResponse(ctx context.Context, w http.ResponseWriter, err error) {
+	code := statusFromError(err)
+	if code == http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("internal server error", "err", err)
+		writeErrorResponse(ctx, w, code, xerrors.ReasonOf(err), fmt.Errorf("there was an internal server error"))
+		return
+	}
+
+	// the cause is hidden from client too, but it is needed to investigate the failure.
+	if cause := xerrors.CauseOf(err); cause != nil {
+		logger.FromContext(ctx).Warn("request failed", "err", err, "cause", cause)
+	}
+
+	// Retry-After is in whole seconds, so it is rounded up to never invite an early retry.
+	if retryAfter := xerrors.RetryAfterOf(err); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10))
+	}
+
+	writeErrorResponse(ctx, w, code, xerrors.ReasonOf(err), err, xerrors.DetailsOf(err)...)
+}
+
+// writeErrorResponse write error to http response with explicit code and reason.
+// Failures of writing are logged by the logger of ctx, so they are correlated with the request.
+func writeErrorResponse(ctx context.Context, w http.ResponseWriter, code int, reason string, err error, details ...string) {
 	resp := &response{
 		Code:    code,
+		Reason:  reason,
 		Message: err.Error(),
+		Details: details,
 	}
 
 	jData, err := json.Marshal(resp)
 	if err != nil {
-		log.Println(err)
+		logger.FromContext(ctx).Error("unable to encode error response", "err", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	log.Println("code", code)
 	w.WriteHeader(code)
 	if _, err := w.Write(jData); err != nil {
-		log.Println(err)
+		logger.FromContext(ctx).Error("unable to write error response", "err", err)
 	}
 }
 
 // dataResponse write response data to http response with passing data.
 // The response status code is fixed to 200.
-func dataResponse(w http.ResponseWriter, data any) {
+func dataResponse(ctx context.Context, w http.ResponseWriter, data any) {
 	resp := &response{
 		Data: data,
 	}
 
 	jData, err := json.Marshal(resp)
 	if err != nil {
-		log.Println(err)
+		logger.FromContext(ctx).Error("unable to encode response", "err", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(jData); err != nil {
-		log.Println(err)
+		logger.FromContext(ctx).Error("unable to write response", "err", err)
 	}
 }
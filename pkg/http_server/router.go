@@ -0,0 +1,251 @@
+package http_server
+
+import (
+	"context"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// route is a registered handler of a method and a path pattern.
+type route struct {
+	method  string
+	pattern string
+	// paramNames are names of wildcard segments by order in pattern.
+	paramNames []string
+	handler    httpHandler
+}
+
+// key returns the route identity that other middlewares use to configure per route.
+func (r *route) key() string {
+	return routeKey(r.method, r.pattern)
+}
+
+// node is a segment of path in [router] tree.
+type node struct {
+	static   map[string]*node
+	param    *node
+	catchAll *node
+
+	// routes by method of the pattern that ends at this node.
+	routes map[string]*route
+}
+
+// router is a tree of path segments, matching cost is bound by the depth of path instead of number of routes.
+// Static segments are preferred over wildcards ("{id}"), wildcards are preferred over catch-all ("{path...}"),
+// a preferred branch without a route of the method falls back to the next one.
+type router struct {
+	root *node
+}
+
+func newRouter() *router {
+	return &router{
+		root: &node{},
+	}
+}
+
+// add registers a handler for method and pattern, registering the same route twice is a programming error.
+func (t *router) add(method, pattern string, handler httpHandler) {
+	n := t.root
+	var paramNames []string
+	segments := splitPath(pattern)
+	for i, seg := range segments {
+		switch {
+		case isCatchAll(seg):
+			if i != len(segments)-1 {
+				log.Fatalf("catch-all segment must be the last segment in %s", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll = &node{}
+			}
+			n = n.catchAll
+			paramNames = append(paramNames, paramName(seg))
+		case isParam(seg):
+			if n.param == nil {
+				n.param = &node{}
+			}
+			n = n.param
+			paramNames = append(paramNames, paramName(seg))
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*node)
+			}
+			child, ok := n.static[seg]
+			if !ok {
+				child = &node{}
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+
+	if n.routes == nil {
+		n.routes = make(map[string]*route)
+	}
+
+	if existed, ok := n.routes[method]; ok {
+		log.Fatalf("route %s conflicts with %s", joinPath(method, pattern), existed.key())
+	}
+
+	n.routes[method] = &route{
+		method:     method,
+		pattern:    pattern,
+		paramNames: paramNames,
+		handler:    handler,
+	}
+}
+
+// lookup returns the routes by method which matched with path and the values of wildcard segments.
+func (t *router) lookup(path string) (map[string]*route, []string) {
+	return t.root.lookup(splitPath(path), nil, func(map[string]*route) bool { return true })
+}
+
+// lookup returns the first routes matched with segments in priority order which are accepted by accept.
+// When routes of a branch are not accepted (ex: a static branch without the method),
+// it falls back to the next branch (wildcard, then catch-all).
+func (n *node) lookup(segments []string, values []string, accept func(map[string]*route) bool) (map[string]*route, []string) {
+	if len(segments) == 0 {
+		if len(n.routes) > 0 && accept(n.routes) {
+			return n.routes, values
+		}
+		// "/files/{path...}" also matches "/files/"
+		if n.catchAll != nil && len(n.catchAll.routes) > 0 && accept(n.catchAll.routes) {
+			return n.catchAll.routes, append(values, "")
+		}
+		return nil, nil
+	}
+
+	seg, rest := segments[0], segments[1:]
+	if child, ok := n.static[seg]; ok {
+		if routes, vals := child.lookup(rest, values, accept); routes != nil {
+			return routes, vals
+		}
+	}
+
+	if n.param != nil && seg != "" {
+		if routes, vals := n.param.lookup(rest, append(values, seg), accept); routes != nil {
+			return routes, vals
+		}
+	}
+
+	if n.catchAll != nil && len(n.catchAll.routes) > 0 && accept(n.catchAll.routes) {
+		return n.catchAll.routes, append(values, strings.Join(segments, slash))
+	}
+
+	return nil, nil
+}
+
+// match returns the route of method and path with wildcard params, the second value is the list of
+// allowed methods of all routes matching path when none of them has the method.
+func (t *router) match(method, path string) (*route, map[string]any, []string) {
+	segments := splitPath(path)
+	routes, values := t.root.lookup(segments, nil, func(routes map[string]*route) bool {
+		_, ok := routeOfMethod(routes, method)
+		return ok
+	})
+	if routes == nil {
+		// rejects every branch, so routes of all branches matching path are collected.
+		all := make(map[string]*route)
+		t.root.lookup(segments, nil, func(routes map[string]*route) bool {
+			maps.Copy(all, routes)
+			return false
+		})
+		if len(all) == 0 {
+			return nil, nil, nil
+		}
+
+		return nil, nil, allowedMethods(all)
+	}
+
+	r, _ := routeOfMethod(routes, method)
+	params := make(map[string]any, len(r.paramNames))
+	for i, name := range r.paramNames {
+		params[name] = values[i]
+	}
+
+	return r, params, nil
+}
+
+// routeOfMethod returns the route of method in routes.
+func routeOfMethod(routes map[string]*route, method string) (*route, bool) {
+	r, ok := routes[method]
+	// HEAD is served by GET handler, [net/http] will discard the body.
+	if !ok && method == http.MethodHead {
+		r, ok = routes[http.MethodGet]
+	}
+
+	return r, ok
+}
+
+// allowedMethods returns the sorted methods of routes, including HEAD and OPTIONS which are served automatically.
+func allowedMethods(routes map[string]*route) []string {
+	methods := []string{http.MethodOptions}
+	for method := range routes {
+		methods = append(methods, method)
+	}
+
+	if _, ok := routes[http.MethodGet]; ok {
+		if _, ok := routes[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	slices.Sort(methods)
+
+	return methods
+}
+
+// routingMiddleware matches the request with routes of server once and imports the matched route
+// and its wildcard params to context, so next middlewares and handler can share the result.
+type routingMiddleware struct {
+	router *router
+}
+
+func (m *routingMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		matched, params, allow := m.router.match(r.Method, r.URL.Path)
+		ctx := context.WithValue(r.Context(), &routeKeyCtx{}, &routeResult{
+			route:  matched,
+			allow:  allow,
+			params: params,
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// routeResult is the result of routing which was imported to context by [routingMiddleware].
+type routeResult struct {
+	route  *route
+	allow  []string
+	params map[string]any
+}
+
+// routeFromContext returns the result of routing, nil if request was not routed.
+func routeFromContext(ctx context.Context) *routeResult {
+	result, _ := ctx.Value(&routeKeyCtx{}).(*routeResult)
+	return result
+}
+
+// matchedRouteKey returns the key of matched route in context, empty if no route matched.
+func matchedRouteKey(ctx context.Context) string {
+	result := routeFromContext(ctx)
+	if result == nil || result.route == nil {
+		return ""
+	}
+
+	return result.route.key()
+}
+
+// RoutePattern returns the pattern of matched route in context (ex: "/users/{id}"), empty if no route matched.
+// It is used instead of the raw path when requests are grouped by route, so the number of groups is bounded.
+func RoutePattern(ctx context.Context) string {
+	result := routeFromContext(ctx)
+	if result == nil || result.route == nil {
+		return ""
+	}
+
+	return result.route.pattern
+}
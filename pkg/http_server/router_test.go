@@ -0,0 +1,157 @@
+package http_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_routerMatch(t *testing.T) {
+	rt := newRouter()
+	for _, route := range []string{
+		"GET /users/{id}",
+		"GET /users/me",
+		"PUT /users/{id}",
+		"GET /users/{user_id}/accounts",
+		"GET /users/me/accounts",
+		"GET /files/{path...}",
+		"GET /files/{id}/meta",
+		"GET /accounts/me",
+		"DELETE /accounts/{id}",
+		"POST /accounts/{id}/transfers",
+		"GET /accounts/{path...}",
+	} {
+		method, pattern := parseRoute(route)
+		rt.add(method, pattern, nil)
+	}
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		wantPattern string
+		wantParams  map[string]any
+		wantAllow   []string
+	}{
+		{
+			name:        "static segment is preferred over wildcard",
+			method:      http.MethodGet,
+			path:        "/users/me",
+			wantPattern: "/users/me",
+			wantParams:  map[string]any{},
+		},
+		{
+			name:        "wildcard",
+			method:      http.MethodGet,
+			path:        "/users/123/",
+			wantPattern: "/users/{id}",
+			wantParams:  map[string]any{"id": "123"},
+		},
+		{
+			name:        "wildcard name by route",
+			method:      http.MethodGet,
+			path:        "/users/123/accounts",
+			wantPattern: "/users/{user_id}/accounts",
+			wantParams:  map[string]any{"user_id": "123"},
+		},
+		{
+			name:        "backtrack to wildcard when static branch does not match",
+			method:      http.MethodGet,
+			path:        "/files/abc/meta",
+			wantPattern: "/files/{id}/meta",
+			wantParams:  map[string]any{"id": "abc"},
+		},
+		{
+			name:        "catch-all",
+			method:      http.MethodGet,
+			path:        "/files/a/b/c.txt",
+			wantPattern: "/files/{path...}",
+			wantParams:  map[string]any{"path": "a/b/c.txt"},
+		},
+		{
+			name:        "head is served by get",
+			method:      http.MethodHead,
+			path:        "/users/123",
+			wantPattern: "/users/{id}",
+			wantParams:  map[string]any{"id": "123"},
+		},
+		{
+			name:      "method not allowed",
+			method:    http.MethodDelete,
+			path:      "/users/123",
+			wantAllow: []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut},
+		},
+		{
+			name:        "fall back to wildcard when static branch has no route of method",
+			method:      http.MethodDelete,
+			path:        "/accounts/me",
+			wantPattern: "/accounts/{id}",
+			wantParams:  map[string]any{"id": "me"},
+		},
+		{
+			name:        "fall back to catch-all when wildcard branch has no route of method",
+			method:      http.MethodGet,
+			path:        "/accounts/123/transfers",
+			wantPattern: "/accounts/{path...}",
+			wantParams:  map[string]any{"path": "123/transfers"},
+		},
+		{
+			name:      "allowed methods of all matched branches",
+			method:    http.MethodPut,
+			path:      "/accounts/me",
+			wantAllow: []string{http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodOptions},
+		},
+		{
+			name:   "not found",
+			method: http.MethodGet,
+			path:   "/transfers/123",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, params, allow := rt.match(tc.method, tc.path)
+			assert.Equal(t, tc.wantAllow, allow)
+			if tc.wantPattern == "" {
+				assert.Nil(t, r)
+				return
+			}
+
+			require.NotNil(t, r)
+			assert.Equal(t, tc.wantPattern, r.pattern)
+			assert.Equal(t, tc.wantParams, params)
+		})
+	}
+}
+
+func Test_serveRoute(t *testing.T) {
+	s := NewHttpServer(nil, nil)
+	rt := s.router
+	rt.add(http.MethodGet, "/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := (&routingMiddleware{router: rt}).Wrap(http.HandlerFunc(s.serveRoute))
+
+	testCases := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantAllow string
+	}{
+		{method: http.MethodGet, path: "/users/1", wantCode: http.StatusOK},
+		{method: http.MethodHead, path: "/users/1", wantCode: http.StatusOK},
+		{method: http.MethodPost, path: "/users/1", wantCode: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD, OPTIONS"},
+		{method: http.MethodOptions, path: "/users/1", wantCode: http.StatusNoContent, wantAllow: "GET, HEAD, OPTIONS"},
+		{method: http.MethodGet, path: "/accounts/1", wantCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.wantCode, w.Code, "%s %s", tc.method, tc.path)
+		assert.Equal(t, tc.wantAllow, w.Header().Get("Allow"), "%s %s", tc.method, tc.path)
+	}
+}
@@ -0,0 +1,61 @@
+package http_server
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "user-management/pkg/http_server"
+
+// tracingMiddleware represents option that records a server span per request.
+type tracingMiddleware struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func (m *tracingMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// continue the trace of caller if the request has a "traceparent" header, otherwise start a new trace.
+		ctx := m.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		name := r.Method
+		attrs := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		}
+		// the span is named by route pattern, so spans of the same route are grouped.
+		if route := RoutePattern(ctx); route != "" {
+			name += " " + route
+			attrs = append(attrs, trace.WithAttributes(semconv.HTTPRoute(route)))
+		}
+
+		ctx, span := m.tracer.Start(ctx, name, attrs...)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		// client errors are expected results of server, only server errors fail the span.
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// WithTracing returns a middleware that records a server span per request by the global tracer provider of otel.
+// The trace context is extracted from W3C "traceparent" header and is available in context of handlers.
+func WithTracing() Middleware {
+	return &tracingMiddleware{
+		tracer:     otel.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}
+}
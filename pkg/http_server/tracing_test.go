@@ -0,0 +1,52 @@
+package http_server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	s := NewHttpServer(nil, nil, &tracingMiddleware{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	})
+	var handlerSpan trace.SpanContext
+	Register(s, http.MethodGet, "/users/{id}", func(ctx context.Context, req *struct {
+		ID int64 `json:"id"`
+	}) (*struct{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return &struct{}{}, nil
+	})
+	handler := s.handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	// the trace of caller is continued and the span is available for handlers.
+	assert.Equal(t, "GET /users/{id}", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "GET", spans[1].Name())
+	assert.False(t, spans[1].Parent().IsValid())
+}
@@ -1,9 +1,7 @@
 package http_server
 
 import (
-	"context"
 	"fmt"
-	"net/http"
 	"strings"
 )
 
@@ -12,34 +10,51 @@ func joinPath(method, path string) string {
 	return fmt.Sprintf("%s %s", method, path)
 }
 
-// isMatchPath returns an bool value with matching from pattern and source by wildcard params.
-// ex: "/users/{id}" will match with "/users/123" but "/users" and "/users/123/accounts" not.
-func isMatchPath(pattern, source string) bool {
-	patternEls := strings.Split(strings.Trim(pattern, slash), slash)
-	sourceEls := strings.Split(strings.Trim(source, slash), slash)
-	if len(patternEls) != len(sourceEls) {
-		return false
-	}
-	isMatch := true
-	for i, el := range patternEls {
-		isMatch = isMatch && (bracketRegex.MatchString(el) || el == sourceEls[i])
+// splitPath returns segments of path without leading and trailing slashes.
+// ex: "/users/123/" will be ["users", "123"] and "/" will be [].
+func splitPath(path string) []string {
+	path = strings.Trim(path, slash)
+	if path == "" {
+		return nil
 	}
 
-	return isMatch
+	return strings.Split(path, slash)
+}
+
+// isParam returns true if the segment is a wildcard (ex: "{id}").
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, openBracket) && strings.HasSuffix(segment, closeBracket)
+}
+
+// isCatchAll returns true if the segment is a catch-all wildcard (ex: "{path...}").
+func isCatchAll(segment string) bool {
+	return isParam(segment) && strings.HasSuffix(segment, catchAllSuffix+closeBracket)
 }
 
-// appendWildCardParams will get wildcard params in request and append to the request context
-func appendWildCardParams(pattern string, r *http.Request) *http.Request {
-	result := make(map[string]any)
-	patternEls := strings.Split(strings.Trim(pattern, slash), slash)
-	sourceEls := strings.Split(strings.Trim(r.URL.Path, slash), slash)
-	for i, el := range patternEls {
-		if bracketRegex.MatchString(el) {
-			val := strings.TrimLeft(el, openBracket)
-			val = strings.TrimRight(val, closeBracket)
-			result[val] = sourceEls[i]
+// paramName returns the name of wildcard segment (ex: "{id}" will be "id" and "{path...}" will be "path").
+func paramName(segment string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(segment, openBracket), closeBracket)
+	return strings.TrimSuffix(name, catchAllSuffix)
+}
+
+// routeKey returns the identity of a route by method and pattern without names of wildcards,
+// so "GET /users/{id}/accounts" and "GET /users/{user_id}/accounts" are the same route.
+func routeKey(method, pattern string) string {
+	segments := splitPath(pattern)
+	for i, seg := range segments {
+		switch {
+		case isCatchAll(seg):
+			segments[i] = openBracket + catchAllSuffix + closeBracket
+		case isParam(seg):
+			segments[i] = openBracket + closeBracket
 		}
 	}
 
-	return r.WithContext(context.WithValue(r.Context(), &wildcardParamsKey{}, result))
+	return joinPath(method, slash+strings.Join(segments, slash))
+}
+
+// parseRoute returns the method and pattern of a route in "METHOD /pattern" format.
+func parseRoute(route string) (string, string) {
+	method, pattern, _ := strings.Cut(route, space)
+	return method, pattern
 }
@@ -1,6 +1,7 @@
 package http_server
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,6 +9,8 @@ import (
 
 func FuzzMatchPath(f *testing.F) {
 	path := "/users/{id}"
+	rt := newRouter()
+	rt.add(http.MethodGet, path, nil)
 
 	testCases := []struct {
 		path string
@@ -36,6 +39,14 @@ func FuzzMatchPath(f *testing.F) {
 	}
 
 	f.Fuzz(func(t *testing.T, a string, b bool) {
-		assert.Equal(t, isMatchPath(path, a), b)
+		routes, _ := rt.lookup(a)
+		assert.Equal(t, routes != nil, b)
 	})
 }
+
+func Test_routeKey(t *testing.T) {
+	assert.Equal(t, "GET /users/{}/accounts", routeKey(http.MethodGet, "/users/{user_id}/accounts"))
+	assert.Equal(t, "GET /users/{}/accounts", routeKey(parseRoute("GET /users/{id}/accounts/")))
+	assert.Equal(t, "GET /files/{...}", routeKey(http.MethodGet, "/files/{path...}"))
+	assert.Equal(t, "GET /", routeKey(http.MethodGet, "/"))
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"user-management/pkg/xerrors"
 )
 
 type UserInfo struct {
+	// TokenID is the unique id (jti) of the token, used to revoke a single token.
+	TokenID   string    `json:"jti"`
 	UserID    int64     `json:"user_id"`
 	Role      string    `json:"role"`
+	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
@@ -21,7 +26,8 @@ func (p *UserInfo) Valid() error {
 }
 
 func (p *UserInfo) AddExpired(expirationTime time.Duration) {
-	p.ExpiredAt = time.Now().Add(expirationTime)
+	p.IssuedAt = time.Now()
+	p.ExpiredAt = p.IssuedAt.Add(expirationTime)
 }
 
 // ImportUserInfoToContext implements import the user info which retrieved from token
@@ -35,7 +41,7 @@ func ExtractUserInfoFromContext(ctx context.Context) (*UserInfo, error) {
 	info, ok := ctx.Value(&userInfoKey{}).(*UserInfo)
 
 	if !ok || info == nil {
-		return nil, fmt.Errorf("authorization is not valid")
+		return nil, xerrors.Unauthenticated("authorization is not valid")
 	}
 
 	return info, nil
@@ -17,4 +17,6 @@ var bracketRegex = regexp.MustCompile(`\{(.*?)\}`)
 type (
 	wildcardParamsKey struct{}
 	userInfoKey       struct{}
+	requestIDKey      struct{}
+	clientIPKey       struct{}
 )
@@ -0,0 +1,29 @@
+package xcontext
+
+import "context"
+
+// ImportRequestIDToContext returns a copy of ctx carrying the id of request.
+func ImportRequestIDToContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, &requestIDKey{}, id)
+}
+
+// ExtractRequestIDFromContext returns the request id which was injected from [ImportRequestIDToContext],
+// it is empty if ctx does not belong to a request.
+func ExtractRequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(&requestIDKey{}).(string)
+
+	return id
+}
+
+// ImportClientIPToContext returns a copy of ctx carrying the ip of client sending the request.
+func ImportClientIPToContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, &clientIPKey{}, ip)
+}
+
+// ExtractClientIPFromContext returns the client ip which was injected from [ImportClientIPToContext],
+// it is empty if ctx does not belong to a request.
+func ExtractClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(&clientIPKey{}).(string)
+
+	return ip
+}
@@ -0,0 +1,229 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/logger"
+	"user-management/pkg/postgres_client"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the postgres channel which invalidations are sent on.
+const Channel = "cache_invalidate"
+
+// pingInterval is how often an idle listener checks its connection, so a dropped connection is detected
+// and reopened even if no notification is received.
+const pingInterval = 90 * time.Second
+
+// message is the payload of a notification, it identifies a key of a registered cache or the whole cache to purge.
+type message struct {
+	Cache string          `json:"cache"`
+	Key   json.RawMessage `json:"key,omitempty"`
+	Purge bool            `json:"purge,omitempty"`
+}
+
+// listener receives notifications of postgres by a dedicated connection, it is implemented by [pq.Listener].
+type listener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+// subscriber removes keys of a registered cache.
+type subscriber struct {
+	remove func(ctx context.Context, key json.RawMessage) error
+	purge  func(ctx context.Context) error
+}
+
+// Bus is a [processor.Processor] which invalidates caches of all instances through postgres LISTEN/NOTIFY.
+// Caches are registered by [Register] before starting, removing a key from a registered cache notifies
+// every instance to remove the key from its own cache.
+type Bus struct {
+	pgClient *postgres_client.PostgresClient
+	logger   logger.Logger
+
+	subscribers map[string]*subscriber
+	// newListener opens the listener of Start, it is replaced by tests.
+	newListener func(callback pq.EventCallbackType) listener
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewBus creates a bus which sends and listens invalidations on [Channel] by pgClient.
+func NewBus(pgClient *postgres_client.PostgresClient, logger logger.Logger) *Bus {
+	return &Bus{
+		pgClient:    pgClient,
+		logger:      logger,
+		subscribers: make(map[string]*subscriber),
+		newListener: func(callback pq.EventCallbackType) listener {
+			return pgClient.NewListener(time.Second, time.Minute, callback)
+		},
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// Register subscribes c to invalidations of name and returns c which also notifies all instances when a key is removed.
+// The local cache c must be used to receive invalidations, so they are not sent again.
+func Register[K comparable, V any](b *Bus, name string, c cache.LoadingCache[K, V]) cache.LoadingCache[K, V] {
+	b.subscribers[name] = &subscriber{
+		remove: func(ctx context.Context, key json.RawMessage) error {
+			var k K
+			if err := json.Unmarshal(key, &k); err != nil {
+				return fmt.Errorf("unable to decode key %s: %w", key, err)
+			}
+
+			return c.Remove(ctx, k)
+		},
+		purge: func(ctx context.Context) error {
+			purger, ok := c.(cache.Purger)
+			if !ok {
+				return fmt.Errorf("cache %T can not be purged", c)
+			}
+
+			return purger.Purge(ctx)
+		},
+	}
+
+	return &notifyingCache[K, V]{LoadingCache: c, bus: b, name: name}
+}
+
+// Publish notifies all instances to remove key from the cache registered as name.
+func (b *Bus) Publish(ctx context.Context, name string, key any) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("unable to encode key %v: %w", key, err)
+	}
+
+	payload, err := json.Marshal(&message{Cache: name, Key: data})
+	if err != nil {
+		return fmt.Errorf("unable to encode invalidation: %w", err)
+	}
+
+	return b.pgClient.Notify(ctx, Channel, string(payload))
+}
+
+// PublishPurge notifies all instances to remove all keys from the cache registered as name.
+func (b *Bus) PublishPurge(ctx context.Context, name string) error {
+	payload, err := json.Marshal(&message{Cache: name, Purge: true})
+	if err != nil {
+		return fmt.Errorf("unable to encode invalidation: %w", err)
+	}
+
+	return b.pgClient.Notify(ctx, Channel, string(payload))
+}
+
+// Start implements [processor.Processor], it listens invalidations until stopped.
+// Listening waits for the connection in background, so a down postgres does not block starting or stopping,
+// caches are purged once listening starts because invalidations sent before were not received.
+func (b *Bus) Start(ctx context.Context) error {
+	defer close(b.doneChan)
+
+	listener := b.newListener(b.onEvent)
+	// closing also unblocks Listen if it is still waiting for the connection.
+	defer listener.Close()
+
+	listened := make(chan error, 1)
+	go func() {
+		listened <- listener.Listen(Channel)
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopChan:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case err := <-listened:
+			if err != nil {
+				return fmt.Errorf("unable to listen channel %s: %w", Channel, err)
+			}
+			// a nil channel is never selected, so the result is only handled once.
+			listened = nil
+			b.logger.Info("listening cache invalidations", "channel", Channel)
+			b.purge(ctx)
+		case n := <-listener.NotificationChannel():
+			// nil is sent after reconnecting, invalidations sent meanwhile were lost.
+			if n == nil {
+				b.purge(ctx)
+				continue
+			}
+			b.handle(ctx, n.Extra)
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// Stop implements [processor.Processor], it stops listening and waits for [Bus.Start] to return.
+func (b *Bus) Stop(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.stopChan) })
+
+	select {
+	case <-b.doneChan:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handle removes the key of payload from its local cache, or purges the local cache.
+func (b *Bus) handle(ctx context.Context, payload string) {
+	var msg message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		b.logger.Warn("invalid cache invalidation", "payload", payload, "err", err)
+		return
+	}
+
+	sub, ok := b.subscribers[msg.Cache]
+	if !ok {
+		// the cache may only be registered by other versions of the service.
+		b.logger.Debug("cache of invalidation is not registered", "cache", msg.Cache)
+		return
+	}
+
+	if msg.Purge {
+		if err := sub.purge(ctx); err != nil {
+			b.logger.Error("unable to purge cache", "cache", msg.Cache, "err", err)
+		}
+		return
+	}
+
+	// the key may be not cached by this instance, it is not a failure.
+	if err := sub.remove(ctx, msg.Key); err != nil && !errors.Is(err, cache.ErrNotFound) {
+		b.logger.Warn("unable to invalidate cache", "cache", msg.Cache, "key", string(msg.Key), "err", err)
+	}
+}
+
+// purge removes all keys of registered caches.
+func (b *Bus) purge(ctx context.Context) {
+	for name, sub := range b.subscribers {
+		if err := sub.purge(ctx); err != nil {
+			b.logger.Error("unable to purge cache", "cache", name, "err", err)
+		}
+	}
+}
+
+// onEvent logs state changes of the listener connection.
+func (b *Bus) onEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		b.logger.Warn("cache invalidation listener disconnected", "err", err)
+	case pq.ListenerEventReconnected:
+		b.logger.Info("cache invalidation listener reconnected, purging caches")
+	case pq.ListenerEventConnectionAttemptFailed:
+		b.logger.Warn("unable to connect cache invalidation listener", "err", err)
+	}
+}
@@ -0,0 +1,136 @@
+package invalidation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/lru"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusHandle(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(nil, slog.Default())
+	users := cache.NewLoader(lru.NewLRU[int64, *cache.Entry[string]](16, time.Minute))
+	userNames := cache.NewLoader(lru.NewLRU[string, *cache.Entry[int64]](16, time.Minute))
+	Register[int64, string](bus, "user", users)
+	Register[string, int64](bus, "user_by_user_name", userNames)
+
+	require.NoError(t, users.Add(ctx, 1, "dat"))
+	require.NoError(t, users.Add(ctx, 2, "tuan"))
+	require.NoError(t, userNames.Add(ctx, "dat", 1))
+
+	bus.handle(ctx, `{"cache":"user","key":1}`)
+	_, err := users.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	_, err = users.Get(ctx, 2)
+	assert.NoError(t, err)
+
+	bus.handle(ctx, `{"cache":"user_by_user_name","key":"dat"}`)
+	_, err = userNames.Get(ctx, "dat")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	// unknown caches, missing keys and invalid payloads are ignored.
+	bus.handle(ctx, `{"cache":"account","key":1}`)
+	bus.handle(ctx, `{"cache":"user","key":1}`)
+	bus.handle(ctx, `{"cache":"user","key":"dat"}`)
+	bus.handle(ctx, `invalid`)
+
+	require.NoError(t, userNames.Add(ctx, "tuan", 2))
+	bus.handle(ctx, `{"cache":"user_by_user_name","purge":true}`)
+	_, err = userNames.Get(ctx, "tuan")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	_, err = users.Get(ctx, 2)
+	assert.NoError(t, err)
+
+	bus.purge(ctx)
+	_, err = users.Get(ctx, 2)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+}
+
+// fakeListener is a listener which connects when connected is closed.
+type fakeListener struct {
+	connected chan struct{}
+	closed    chan struct{}
+	notify    chan *pq.Notification
+}
+
+func newFakeListener() *fakeListener {
+	return &fakeListener{
+		connected: make(chan struct{}),
+		closed:    make(chan struct{}),
+		notify:    make(chan *pq.Notification),
+	}
+}
+
+func (l *fakeListener) Listen(string) error {
+	select {
+	case <-l.connected:
+		return nil
+	case <-l.closed:
+		return errors.New("listener closed")
+	}
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification { return l.notify }
+func (l *fakeListener) Ping() error                                  { return nil }
+func (l *fakeListener) Close() error {
+	close(l.closed)
+	return nil
+}
+
+func startBus(t *testing.T, l *fakeListener) (*Bus, *cache.Loader[int64, string]) {
+	bus := NewBus(nil, slog.Default())
+	bus.newListener = func(pq.EventCallbackType) listener { return l }
+	users := cache.NewLoader(lru.NewLRU[int64, *cache.Entry[string]](16, time.Minute))
+	Register[int64, string](bus, "user", users)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- bus.Start(context.Background())
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, bus.Stop(ctx))
+		require.NoError(t, <-errChan)
+	})
+
+	return bus, users
+}
+
+func TestBusStopWhileConnecting(t *testing.T) {
+	l := newFakeListener()
+	startBus(t, l)
+
+	// the listener never connects, stopping must not wait for it.
+}
+
+func TestBusPurgesAfterReconnect(t *testing.T) {
+	ctx := context.Background()
+	l := newFakeListener()
+	_, users := startBus(t, l)
+
+	// invalidations sent before listening were not received.
+	require.NoError(t, users.Add(ctx, 1, "dat"))
+	close(l.connected)
+	assert.Eventually(t, func() bool {
+		_, err := users.Get(ctx, 1)
+		return errors.Is(err, cache.ErrNotFound)
+	}, time.Second, time.Millisecond)
+
+	// a notification of key 1 is missed while the connection drops, nil is sent after reconnecting.
+	require.NoError(t, users.Add(ctx, 1, "dat"))
+	l.notify <- nil
+	assert.Eventually(t, func() bool {
+		_, err := users.Get(ctx, 1)
+		return errors.Is(err, cache.ErrNotFound)
+	}, time.Second, time.Millisecond)
+}
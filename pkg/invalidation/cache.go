@@ -0,0 +1,56 @@
+package invalidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"user-management/pkg/cache"
+)
+
+// notifyingCache is a [cache.LoadingCache] which notifies all instances by [Bus] when a key is removed.
+type notifyingCache[K comparable, V any] struct {
+	cache.LoadingCache[K, V]
+
+	bus  *Bus
+	name string
+}
+
+// Remove is implementation of Remove by [notifyingCache] in [cache.Cache].
+// The key is removed locally at once and from other instances when they receive the notification.
+func (c *notifyingCache[K, V]) Remove(ctx context.Context, k K) error {
+	err := c.LoadingCache.Remove(ctx, k)
+
+	// other instances may cache the key even if this instance does not.
+	if publishErr := c.bus.Publish(ctx, c.name, k); publishErr != nil {
+		return errors.Join(err, publishErr)
+	}
+
+	return err
+}
+
+// Purge is implementation of Purge by [notifyingCache] in [cache.Purger].
+// All keys are removed locally at once and from other instances when they receive the notification.
+func (c *notifyingCache[K, V]) Purge(ctx context.Context) error {
+	purger, ok := c.LoadingCache.(cache.Purger)
+	if !ok {
+		return fmt.Errorf("cache %T can not be purged", c.LoadingCache)
+	}
+
+	err := purger.Purge(ctx)
+	if publishErr := c.bus.PublishPurge(ctx, c.name); publishErr != nil {
+		return errors.Join(err, publishErr)
+	}
+
+	return err
+}
+
+// Len is implementation of Len by [notifyingCache] in [cache.Sizer], it only counts values of this instance.
+func (c *notifyingCache[K, V]) Len(ctx context.Context) (int, error) {
+	sizer, ok := c.LoadingCache.(cache.Sizer)
+	if !ok {
+		return 0, fmt.Errorf("cache %T can not be counted", c.LoadingCache)
+	}
+
+	return sizer.Len(ctx)
+}
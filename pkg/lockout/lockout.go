@@ -0,0 +1,176 @@
+// Package lockout tracks failed attempts by key (ex: username or ip) and locks a key out
+// with exponential back-off after too many failures.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/xerrors"
+)
+
+// Counter counts failures of keys, failures are incremented atomically so concurrent failures are all counted.
+// Failures of a key are forgotten after the ttl of counter since its last failure.
+type Counter interface {
+	// Incr increments failures of key and returns the incremented failures.
+	Incr(ctx context.Context, key string) (int64, error)
+	// Remove forgets failures of key, it returns [cache.ErrNotFound] (wrapped) if key has no failures.
+	Remove(ctx context.Context, key string) error
+}
+
+// memoryCounter is a [Counter] of values in a cache in memory, increments are serialized by a mutex.
+type memoryCounter struct {
+	mu    sync.Mutex
+	cache cache.Cache[string, int64]
+}
+
+// NewMemoryCounter creates a counter storing failures in c, c must be a cache in memory (ex: lru)
+// because increments are only atomic within the process.
+func NewMemoryCounter(c cache.Cache[string, int64]) Counter {
+	return &memoryCounter{cache: c}
+}
+
+// Incr is implementation of Incr by [memoryCounter] in [Counter]
+func (c *memoryCounter) Incr(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures, err := c.cache.Get(ctx, key)
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return 0, err
+	}
+
+	failures++
+	if err := c.cache.Add(ctx, key, failures); err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// Remove is implementation of Remove by [memoryCounter] in [Counter]
+func (c *memoryCounter) Remove(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Remove(ctx, key)
+}
+
+// Policy is when a key is locked and for how long.
+type Policy struct {
+	// MaxFailures is the number of failures locking a key, zero disables the lockout.
+	MaxFailures int
+	// BaseLockout is the lockout after MaxFailures failures, it is doubled by every further failure.
+	BaseLockout time.Duration
+	// MaxLockout caps the lockout.
+	MaxLockout time.Duration
+}
+
+// validate returns an error if the policy would never lock a key although MaxFailures is set.
+func (p Policy) validate() error {
+	switch {
+	case p.MaxFailures < 0:
+		return errors.New("max failures of lockout must not be negative")
+	case p.BaseLockout <= 0:
+		return errors.New("base lockout must be greater than zero")
+	case p.MaxLockout < p.BaseLockout:
+		return errors.New("max lockout must not be less than base lockout")
+	}
+
+	return nil
+}
+
+// lockout returns how long a key is locked after failures, zero if it is not locked.
+func (p Policy) lockout(failures int64) time.Duration {
+	if p.MaxFailures <= 0 || failures < int64(p.MaxFailures) {
+		return 0
+	}
+
+	d := p.BaseLockout
+	for i := int64(p.MaxFailures); i < failures && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+
+	return min(d, p.MaxLockout)
+}
+
+// Tracker tracks failed attempts of keys with a prefix, so trackers of different keys can share a counter and locks.
+// The lockout of a failure is decided by the failures returned by its increment, so concurrent failures
+// of a key are all counted and lock it as soon as they reach the policy. Lockouts of concurrent failures
+// may be stored out of order, then the key is locked by the shorter one until its next failure.
+type Tracker struct {
+	failures Counter
+	// locks are the times until that keys are locked, its ttl must outlive the longest lockout.
+	locks  cache.Cache[string, time.Time]
+	prefix string
+	policy Policy
+	now    func() time.Time
+}
+
+// NewTracker creates a tracker counting failures of keys as prefix+key in failures and storing their lockouts in locks.
+// It returns an error if the lockouts of policy are not positive, a zero lockout would never lock a key.
+func NewTracker(failures Counter, locks cache.Cache[string, time.Time], prefix string, policy Policy) (*Tracker, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Tracker{
+		failures: failures,
+		locks:    locks,
+		prefix:   prefix,
+		policy:   policy,
+		now:      time.Now,
+	}, nil
+}
+
+// Check returns an error of [xerrors.KindTooManyRequests] with the remaining lockout as retry after if key is locked.
+func (t *Tracker) Check(ctx context.Context, key string) error {
+	lockedUntil, err := t.locks.Get(ctx, t.prefix+key)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve lockout: %w", err)
+	}
+
+	if remaining := lockedUntil.Sub(t.now()); remaining > 0 {
+		return xerrors.TooManyRequests("too many failed attempts, try again in %s", remaining.Round(time.Second)).WithRetryAfter(remaining)
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt of key and locks it if failures reach the policy.
+func (t *Tracker) Fail(ctx context.Context, key string) error {
+	failures, err := t.failures.Incr(ctx, t.prefix+key)
+	if err != nil {
+		return fmt.Errorf("unable to record failed attempt: %w", err)
+	}
+
+	d := t.policy.lockout(failures)
+	if d <= 0 {
+		return nil
+	}
+
+	if err := t.locks.Add(ctx, t.prefix+key, t.now().Add(d)); err != nil {
+		return fmt.Errorf("unable to lock key: %w", err)
+	}
+
+	return nil
+}
+
+// Reset forgets failed attempts of key and unlocks it.
+func (t *Tracker) Reset(ctx context.Context, key string) error {
+	if err := t.failures.Remove(ctx, t.prefix+key); err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return fmt.Errorf("unable to reset failed attempts: %w", err)
+	}
+	if err := t.locks.Remove(ctx, t.prefix+key); err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return fmt.Errorf("unable to reset lockout: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"user-management/pkg/lru"
+	"user-management/pkg/xerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyLockout(t *testing.T) {
+	p := Policy{MaxFailures: 3, BaseLockout: time.Minute, MaxLockout: 5 * time.Minute}
+
+	assert.Zero(t, p.lockout(2))
+	assert.Equal(t, time.Minute, p.lockout(3))
+	assert.Equal(t, 2*time.Minute, p.lockout(4))
+	assert.Equal(t, 4*time.Minute, p.lockout(5))
+	assert.Equal(t, 5*time.Minute, p.lockout(6))
+	assert.Equal(t, 5*time.Minute, p.lockout(1000))
+
+	assert.Zero(t, Policy{}.lockout(1000))
+}
+
+func TestNewTrackerInvalidPolicy(t *testing.T) {
+	for _, p := range []Policy{
+		{MaxFailures: -1, BaseLockout: time.Minute, MaxLockout: time.Hour},
+		{MaxFailures: 3, MaxLockout: time.Hour},
+		{MaxFailures: 3, BaseLockout: time.Minute},
+		{MaxFailures: 3, BaseLockout: time.Hour, MaxLockout: time.Minute},
+	} {
+		_, err := NewTracker(nil, nil, "", p)
+		assert.Error(t, err, "%+v", p)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	failures := NewMemoryCounter(lru.NewLRU[string, int64](16, time.Hour))
+	locks := lru.NewLRU[string, time.Time](16, time.Hour)
+	users, err := NewTracker(failures, locks, "user:", Policy{MaxFailures: 2, BaseLockout: time.Minute, MaxLockout: time.Hour})
+	require.NoError(t, err)
+	users.now = func() time.Time { return now }
+	ips, err := NewTracker(failures, locks, "ip:", Policy{MaxFailures: 10, BaseLockout: time.Minute, MaxLockout: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, users.Fail(ctx, "dat"))
+	require.NoError(t, users.Check(ctx, "dat"))
+
+	require.NoError(t, users.Fail(ctx, "dat"))
+	err = users.Check(ctx, "dat")
+	require.Error(t, err)
+	assert.Equal(t, xerrors.KindTooManyRequests, xerrors.KindOf(err))
+	assert.Equal(t, time.Minute, xerrors.RetryAfterOf(err))
+
+	// keys of other prefixes and other users are not locked.
+	require.NoError(t, ips.Check(ctx, "dat"))
+	require.NoError(t, users.Check(ctx, "tuan"))
+
+	// the lockout is doubled by a failure after it is expired.
+	now = now.Add(time.Minute)
+	require.NoError(t, users.Check(ctx, "dat"))
+	require.NoError(t, users.Fail(ctx, "dat"))
+	assert.Equal(t, 2*time.Minute, xerrors.RetryAfterOf(users.Check(ctx, "dat")))
+
+	require.NoError(t, users.Reset(ctx, "dat"))
+	require.NoError(t, users.Check(ctx, "dat"))
+	require.NoError(t, users.Reset(ctx, "dat"))
+}
+
+func TestTrackerConcurrentFailures(t *testing.T) {
+	ctx := context.Background()
+	failures := NewMemoryCounter(lru.NewLRU[string, int64](16, time.Hour))
+	users, err := NewTracker(failures, lru.NewLRU[string, time.Time](16, time.Hour), "user:", Policy{MaxFailures: 50, BaseLockout: time.Minute, MaxLockout: time.Minute})
+	require.NoError(t, err)
+
+	// every concurrent failure is counted, so the key is locked by exactly MaxFailures failures.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, users.Fail(ctx, "dat"))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, xerrors.KindTooManyRequests, xerrors.KindOf(users.Check(ctx, "dat")))
+}
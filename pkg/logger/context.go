@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, it is retrieved by [FromContext].
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, so logs of a request share its attributes (ex: request id).
+// The default logger of [log/slog] is returned if ctx does not carry a logger.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+
+	return slog.Default()
+}
+
+// With returns a logger that includes args in every log of l.
+func With(l Logger, args ...any) Logger {
+	if sl, ok := l.(*slog.Logger); ok {
+		return sl.With(args...)
+	}
+
+	return &withLogger{next: l, args: args}
+}
+
+// withLogger is a [Logger] that prepends its args to args of every log.
+type withLogger struct {
+	next Logger
+	args []any
+}
+
+func (l *withLogger) Debug(msg string, args ...any) { l.next.Debug(msg, l.with(args)...) }
+func (l *withLogger) Error(msg string, args ...any) { l.next.Error(msg, l.with(args)...) }
+func (l *withLogger) Warn(msg string, args ...any)  { l.next.Warn(msg, l.with(args)...) }
+func (l *withLogger) Info(msg string, args ...any)  { l.next.Info(msg, l.with(args)...) }
+
+func (l *withLogger) with(args []any) []any {
+	return append(append(make([]any, 0, len(l.args)+len(args)), l.args...), args...)
+}
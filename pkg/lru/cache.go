@@ -12,18 +12,28 @@ import (
 // lru is presentation of implementing lru memories cache of [cache.Cache]
 type lru[K comparable, V any] struct {
 	*expirable.LRU[K, V]
+
+	metrics *cacheMetrics
 }
 
-func NewLRU[K comparable, V any](size int, ttl time.Duration) cache.Cache[K, V] {
+// NewLRU creates a cache keeping at most size values for ttl.
+func NewLRU[K comparable, V any](size int, ttl time.Duration, opts ...Option) cache.Cache[K, V] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &lru[K, V]{
-		expirable.NewLRU[K, V](size, nil, ttl),
+		LRU:     expirable.NewLRU[K, V](size, nil, ttl),
+		metrics: o.metrics,
 	}
 }
 
 // Add is implementation of Add by [lru] in [cache.Cache]
 func (c *lru[K, V]) Add(_ context.Context, k K, v V) error {
-	if ok := c.LRU.Add(k, v); !ok {
-		return fmt.Errorf("unable to add value to lru")
+	// the returned value only reports whether the oldest entry was evicted to make room.
+	if evicted := c.LRU.Add(k, v); evicted {
+		c.metrics.evict()
 	}
 
 	return nil
@@ -33,17 +43,32 @@ func (c *lru[K, V]) Add(_ context.Context, k K, v V) error {
 func (c *lru[K, V]) Get(_ context.Context, k K) (V, error) {
 	v, ok := c.LRU.Get(k)
 	if !ok {
-		return v, fmt.Errorf("value of %v does not exists", k)
+		c.metrics.miss()
+		return v, fmt.Errorf("value of %v does not exists: %w", k, cache.ErrNotFound)
 	}
 
+	c.metrics.hit()
+
 	return v, nil
 }
 
 // Remove is implementation of Remove by [lru] in [cache.Cache]
 func (c *lru[K, V]) Remove(_ context.Context, k K) error {
 	if ok := c.LRU.Remove(k); !ok {
-		return fmt.Errorf("unable to remove value of %v from lru", k)
+		return fmt.Errorf("unable to remove value of %v from lru: %w", k, cache.ErrNotFound)
 	}
 
 	return nil
 }
+
+// Purge is implementation of Purge by [lru] in [cache.Purger]
+func (c *lru[K, V]) Purge(_ context.Context) error {
+	c.LRU.Purge()
+
+	return nil
+}
+
+// Len is implementation of Len by [lru] in [cache.Sizer], expired values waiting for cleanup are also counted.
+func (c *lru[K, V]) Len(_ context.Context) (int, error) {
+	return c.LRU.Len(), nil
+}
@@ -0,0 +1,77 @@
+package lru
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option is an option to custom behaviors of lru cache.
+type Option func(*options)
+
+type options struct {
+	metrics *cacheMetrics
+}
+
+// Metrics are counters of lru caches labeled by cache name, they are registered once and shared by caches.
+type Metrics struct {
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+}
+
+// NewMetrics registers cache_hits_total, cache_misses_total and cache_evictions_total to registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	labels := []string{"cache"}
+	m := &Metrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Number of lookups which found the value in cache.",
+		}, labels),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Number of lookups which did not find the value in cache, including expired values.",
+		}, labels),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Number of values evicted to make room for new values.",
+		}, labels),
+	}
+	registerer.MustRegister(m.hits, m.misses, m.evictions)
+
+	return m
+}
+
+// WithMetrics records hits, misses and evictions of the cache to m as name.
+func WithMetrics(m *Metrics, name string) Option {
+	return func(o *options) {
+		o.metrics = &cacheMetrics{
+			hits:      m.hits.WithLabelValues(name),
+			misses:    m.misses.WithLabelValues(name),
+			evictions: m.evictions.WithLabelValues(name),
+		}
+	}
+}
+
+// cacheMetrics are counters of a single cache, nil records nothing.
+type cacheMetrics struct {
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	evictions prometheus.Counter
+}
+
+func (m *cacheMetrics) hit() {
+	if m != nil {
+		m.hits.Inc()
+	}
+}
+
+func (m *cacheMetrics) miss() {
+	if m != nil {
+		m.misses.Inc()
+	}
+}
+
+func (m *cacheMetrics) evict() {
+	if m != nil {
+		m.evictions.Inc()
+	}
+}
@@ -0,0 +1,28 @@
+package lru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := NewMetrics(prometheus.NewRegistry())
+	c := NewLRU[int64, string](1, time.Minute, WithMetrics(m, "user"))
+	NewLRU[int64, string](1, time.Minute, WithMetrics(m, "account"))
+
+	c.Add(ctx, 1, "dat")
+	c.Get(ctx, 1)
+	c.Add(ctx, 2, "tuan")
+	c.Get(ctx, 1)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.hits.WithLabelValues("user")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.misses.WithLabelValues("user")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.evictions.WithLabelValues("user")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.hits.WithLabelValues("account")))
+}
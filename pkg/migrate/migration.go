@@ -0,0 +1,103 @@
+package migrate
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// fileNameRegexp matches migration file names such as "00001_migrate.up.sql".
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a presentation of a version of schema with scripts to migrate up to it and down from it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load returns migrations read from the root of fsys sorted by version.
+// Every version must have both up and down script.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[int64]*Migration{}
+	// files by version and direction, a script can be empty so it is not enough to check the content.
+	files := map[int64]map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("migration file name %q is not valid", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file name %q is not valid: %w", entry.Name(), err)
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			migrations[version] = m
+			files[version] = map[string]bool{}
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d has different names %q and %q", version, m.Name, matches[2])
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		files[version][matches[3]] = true
+		if matches[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	result := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if !files[m.Version]["up"] || !files[m.Version]["down"] {
+			return nil, fmt.Errorf("migration version %d must have both up and down script", m.Version)
+		}
+		result = append(result, m)
+	}
+
+	slices.SortFunc(result, func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return result, nil
+}
+
+// FileNames returns the up and down file names of a new migration after the given migrations.
+func FileNames(migrations []*Migration, name string) (string, string) {
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	prefix := fmt.Sprintf("%05d_%s", version, name)
+
+	return prefix + upSuffix, prefix + downSuffix
+}
@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"00002_add_index.up.sql":   {Data: []byte("CREATE INDEX a_idx ON a(id);")},
+		"00002_add_index.down.sql": {Data: []byte("DROP INDEX a_idx;")},
+		"00001_init.up.sql":        {Data: []byte("CREATE TABLE a (id BIGINT);")},
+		"00001_init.down.sql":      {Data: []byte("DROP TABLE a;")},
+		"embed.go":                 {Data: []byte("package migrations")},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, &Migration{Version: 1, Name: "init", Up: "CREATE TABLE a (id BIGINT);", Down: "DROP TABLE a;"}, migrations[0])
+	assert.Equal(t, int64(2), migrations[1].Version)
+
+	up, down := FileNames(migrations, "add_column")
+	assert.Equal(t, "00003_add_column.up.sql", up)
+	assert.Equal(t, "00003_add_column.down.sql", down)
+
+	_, err = Load(fstest.MapFS{"00001_init.up.sql": {Data: []byte("CREATE TABLE a (id BIGINT);")}})
+	assert.Error(t, err, "missing down script")
+
+	_, err = Load(fstest.MapFS{"init.sql": {}})
+	assert.Error(t, err, "invalid file name")
+
+	// created files are empty until written.
+	_, err = Load(fstest.MapFS{"00001_init.up.sql": {}, "00001_init.down.sql": {}})
+	assert.NoError(t, err)
+}
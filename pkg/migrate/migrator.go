@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// lockID is the key of the postgres advisory lock held while migrating,
+// so instances started at the same time do not apply the same migration twice.
+const lockID int64 = 7_563_210_424_118_052_801
+
+// Migrator applies migrations to postgres. The current version is kept in a single row of schema_migrations table
+// which is compatible with golang-migrate, so a database migrated by its docker image can be continued.
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// Status is a presentation of a migration and whether it was applied.
+type Status struct {
+	*Migration
+	Applied bool
+}
+
+// New creates a new Migrator with migrations sorted by version, see [Load].
+func New(db *sql.DB, migrations []*Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies at most steps migrations after the current version, all of them if steps is not positive.
+// It returns the applied migrations even if an error occurred.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	var applied []*Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, current int64) error {
+		var err error
+		applied, err = m.up(ctx, conn, current, math.MaxInt64, steps)
+		return err
+	})
+
+	return applied, err
+}
+
+// Down reverts at most steps migrations from the current version, all of them if steps is not positive.
+// It returns the reverted migrations even if an error occurred.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	var reverted []*Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, current int64) error {
+		var err error
+		reverted, err = m.down(ctx, conn, current, 0, steps)
+		return err
+	})
+
+	return reverted, err
+}
+
+// Goto migrates up or down until the current version is the given version, 0 means reverting all migrations.
+// It returns the applied or reverted migrations even if an error occurred.
+func (m *Migrator) Goto(ctx context.Context, version int64) ([]*Migration, error) {
+	if version != 0 && m.indexOf(version) < 0 {
+		return nil, fmt.Errorf("migration version %d does not exist", version)
+	}
+
+	var result []*Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, current int64) error {
+		var err error
+		if version >= current {
+			result, err = m.up(ctx, conn, current, version, 0)
+		} else {
+			result, err = m.down(ctx, conn, current, version, 0)
+		}
+		return err
+	})
+
+	return result, err
+}
+
+// Status returns all migrations with whether they were applied and the current version.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, int64, error) {
+	var result []*Status
+	var version int64
+	err := m.withLock(ctx, func(conn *sql.Conn, current int64) error {
+		version = current
+		for _, migration := range m.migrations {
+			result = append(result, &Status{
+				Migration: migration,
+				Applied:   migration.Version <= current,
+			})
+		}
+
+		return nil
+	})
+
+	return result, version, err
+}
+
+// withLock calls fn with a connection holding the advisory lock and the current version, 0 if nothing was applied.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, current int64) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// session level lock, it is released by unlock or closing the connection.
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return fmt.Errorf("unable to create schema_migrations table: %w", err)
+	}
+
+	var current int64
+	var dirty bool
+	err = conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unable to get current version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d, fix it manually and then clear the dirty flag", current)
+	}
+
+	if current != 0 && m.indexOf(current) < 0 {
+		return fmt.Errorf("current version %d does not exist in migrations", current)
+	}
+
+	return fn(conn, current)
+}
+
+// up applies at most steps migrations with version in (current, target] in ascending order.
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, current, target int64, steps int) ([]*Migration, error) {
+	var applied []*Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= current || migration.Version > target {
+			continue
+		}
+		if steps > 0 && len(applied) == steps {
+			break
+		}
+
+		if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+			return applied, fmt.Errorf("unable to apply migration %d: %w", migration.Version, err)
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// down reverts at most steps migrations with version in (target, current] in descending order.
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, current, target int64, steps int) ([]*Migration, error) {
+	var reverted []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		if steps > 0 && len(reverted) == steps {
+			break
+		}
+
+		if err := m.apply(ctx, conn, migration.Down, m.previousVersion(i)); err != nil {
+			return reverted, fmt.Errorf("unable to revert migration %d: %w", migration.Version, err)
+		}
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// apply runs script and sets the current version in the same transaction, 0 means no version.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, script string, version int64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+
+	if version != 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// previousVersion returns the version before the migration at index i, 0 if it is the first one.
+func (m *Migrator) previousVersion(i int) int64 {
+	if i == 0 {
+		return 0
+	}
+
+	return m.migrations[i-1].Version
+}
+
+// indexOf returns the index of migration by version, -1 if not found.
+func (m *Migrator) indexOf(version int64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
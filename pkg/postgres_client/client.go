@@ -3,15 +3,23 @@ package postgres_client
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 
+	"user-management/pkg/database"
+
 	_ "github.com/lib/pq"
 )
 
+var errNotConnected = errors.New("postgres is not connected")
+
 // PostgresClient is presentation for a custom client of postgres with [database/sql] based.
 type PostgresClient struct {
 	*sql.DB
 	connectionString string
+
+	// executor is the connected DB recording a span per statement.
+	executor database.Executor
 }
 
 // New creates a new PostgresClient using the given connection string.
@@ -29,6 +37,8 @@ func (c *PostgresClient) Connect(ctx context.Context) error {
 		return err
 	}
 
+	c.executor = database.WithTracing(c.DB)
+
 	if err := c.DB.Ping(); err != nil {
 		return err
 	}
@@ -38,7 +48,35 @@ func (c *PostgresClient) Connect(ctx context.Context) error {
 	return nil
 }
 
+// ExecContext overrides ExecContext of [sql.DB] to record a span per statement.
+func (c *PostgresClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.executor.ExecContext(ctx, query, args...)
+}
+
+// QueryContext overrides QueryContext of [sql.DB] to record a span per statement.
+func (c *PostgresClient) QueryContext(ctx context.Context, query string, args ...any) (database.Rows, error) {
+	return c.executor.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext overrides QueryRowContext of [sql.DB] to record a span per statement.
+func (c *PostgresClient) QueryRowContext(ctx context.Context, query string, args ...any) database.Row {
+	return c.executor.QueryRowContext(ctx, query, args...)
+}
+
+// HealthCheck implements ping postgres by [PostgresClient] in [processor.HealthChecker].
+func (c *PostgresClient) HealthCheck(ctx context.Context) error {
+	if c.DB == nil {
+		return errNotConnected
+	}
+
+	return c.DB.PingContext(ctx)
+}
+
 // Close implements close postgres connection by [PostgresClient]..
 func (c *PostgresClient) Close(ctx context.Context) error {
+	if c.DB == nil {
+		return nil
+	}
+
 	return c.DB.Close()
 }
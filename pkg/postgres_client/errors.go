@@ -0,0 +1,60 @@
+package postgres_client
+
+import (
+	"database/sql"
+	"errors"
+
+	"user-management/pkg/xerrors"
+
+	"github.com/lib/pq"
+)
+
+// Reasons of errors mapped from postgres errors by [WrapError].
+const (
+	ReasonDuplicateValue      = "DUPLICATE_VALUE"
+	ReasonReferenceNotFound   = "REFERENCE_NOT_FOUND"
+	ReasonConstraintViolation = "CONSTRAINT_VIOLATION"
+	ReasonValueRequired       = "VALUE_REQUIRED"
+	ReasonInvalidFormat       = "INVALID_FORMAT"
+	ReasonConcurrentUpdate    = "CONCURRENT_UPDATE"
+)
+
+// WrapError returns a typed error of [xerrors] mapped from a [database/sql] or postgres error.
+// It returns nil if err is nil and keeps err already typed as it is.
+func WrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var xerr *xerrors.Error
+	if errors.As(err, &xerr) {
+		return err
+	}
+
+	// messages returned to clients are fixed, texts of the driver name tables and values,
+	// so they are only kept as the cause to be logged.
+	if errors.Is(err, sql.ErrNoRows) {
+		return xerrors.NotFound("value does not exist").WithCause(err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return xerrors.Conflict("value already exists").WithReason(ReasonDuplicateValue).WithCause(err)
+		case "foreign_key_violation":
+			return xerrors.InvalidArgument("referenced value does not exist").WithReason(ReasonReferenceNotFound).WithCause(err)
+		case "check_violation":
+			return xerrors.InvalidArgument("value is out of the allowed range").WithReason(ReasonConstraintViolation).WithCause(err)
+		case "not_null_violation":
+			return xerrors.InvalidArgument("required value is missing").WithReason(ReasonValueRequired).WithCause(err)
+		case "invalid_text_representation":
+			return xerrors.InvalidArgument("value is not well formatted").WithReason(ReasonInvalidFormat).WithCause(err)
+		case "serialization_failure", "deadlock_detected":
+			// the client can retry the same request.
+			return xerrors.Conflict("value was updated concurrently, please retry").WithReason(ReasonConcurrentUpdate).WithCause(err)
+		}
+	}
+
+	return xerrors.Internal("%w", err)
+}
@@ -0,0 +1,75 @@
+package postgres_client
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"user-management/pkg/xerrors"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		kind    xerrors.Kind
+		reason  string
+		message string
+	}{
+		{
+			name:    "unique violation",
+			err:     &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "users_user_name_key"`},
+			kind:    xerrors.KindConflict,
+			reason:  ReasonDuplicateValue,
+			message: "value already exists",
+		},
+		{
+			name:    "foreign key violation",
+			err:     fmt.Errorf("insert account: %w", &pq.Error{Code: "23503", Message: `insert or update on table "accounts" violates foreign key constraint`}),
+			kind:    xerrors.KindInvalidArgument,
+			reason:  ReasonReferenceNotFound,
+			message: "referenced value does not exist",
+		},
+		{
+			name:    "check violation",
+			err:     &pq.Error{Code: "23514", Message: `new row for relation "accounts" violates check constraint "balance_check"`},
+			kind:    xerrors.KindInvalidArgument,
+			reason:  ReasonConstraintViolation,
+			message: "value is out of the allowed range",
+		},
+		{
+			name:    "serialization failure",
+			err:     &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"},
+			kind:    xerrors.KindConflict,
+			reason:  ReasonConcurrentUpdate,
+			message: "value was updated concurrently, please retry",
+		},
+		{
+			name:    "no rows",
+			err:     sql.ErrNoRows,
+			kind:    xerrors.KindNotFound,
+			reason:  "NOT_FOUND",
+			message: "value does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapError(tt.err)
+
+			assert.Equal(t, tt.kind, xerrors.KindOf(err))
+			assert.Equal(t, tt.reason, xerrors.ReasonOf(err))
+			assert.Equal(t, tt.message, err.Error())
+			// the driver error is kept for logs and matching.
+			var pqErr *pq.Error
+			assert.Equal(t, errors.As(tt.err, &pqErr), errors.As(err, &pqErr))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.Nil(t, WrapError(nil))
+}
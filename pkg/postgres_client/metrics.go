@@ -0,0 +1,25 @@
+package postgres_client
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// metricsDBName is the db_name label of [database/sql.DBStats] metrics.
+const metricsDBName = "postgres"
+
+// Describe implements [prometheus.Collector] by [PostgresClient].
+func (c *PostgresClient) Describe(ch chan<- *prometheus.Desc) {
+	// only descriptions are read, so the collector does not need a connected db.
+	collectors.NewDBStatsCollector(nil, metricsDBName).Describe(ch)
+}
+
+// Collect implements [prometheus.Collector] by [PostgresClient] with [database/sql.DBStats] of the connection pool,
+// nothing is collected before connecting.
+func (c *PostgresClient) Collect(ch chan<- prometheus.Metric) {
+	if c.DB == nil {
+		return
+	}
+
+	collectors.NewDBStatsCollector(c.DB, metricsDBName).Collect(ch)
+}
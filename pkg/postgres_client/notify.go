@@ -0,0 +1,22 @@
+package postgres_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Notify sends payload to all listeners of channel (including this client) by NOTIFY of postgres.
+func (c *PostgresClient) Notify(ctx context.Context, channel, payload string) error {
+	_, err := c.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+
+	return WrapError(err)
+}
+
+// NewListener creates a listener with a dedicated connection for LISTEN.
+// The connection is reopened after it drops with an interval growing from minReconnect to maxReconnect,
+// a nil notification is sent after reconnecting because notifications may have been lost meanwhile.
+func (c *PostgresClient) NewListener(minReconnect, maxReconnect time.Duration, callback pq.EventCallbackType) *pq.Listener {
+	return pq.NewListener(c.connectionString, minReconnect, maxReconnect, callback)
+}
@@ -3,21 +3,50 @@ package postgres_client
 import (
 	"context"
 	"database/sql"
+
+	"user-management/pkg/database"
 )
 
-// Transaction implements a passing function with parameter have pointer of sql.Tx.
+type afterCommitKey struct{}
+
+// afterCommitHooks are functions waiting for the transaction of context to be committed.
+type afterCommitHooks struct {
+	fns []func(ctx context.Context)
+}
+
+// Transaction implements a passing function with parameter have the executor of transaction.
 // The transaction begin with serializable isolation and then call passing function and then commit or rollback.
-func (c *PostgresClient) Transaction(ctx context.Context, fn func(ctx context.Context, db *sql.Tx) error) error {
+// Functions registered by [AfterCommit] with the context of passing function are called after commit.
+func (c *PostgresClient) Transaction(ctx context.Context, fn func(ctx context.Context, db database.Executor) error) error {
 	tx, err := c.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		return err
+		return WrapError(err)
 	}
 	defer tx.Rollback()
-	if err := fn(ctx, tx); err != nil {
+
+	hooks := &afterCommitHooks{}
+	if err := fn(context.WithValue(ctx, afterCommitKey{}, hooks), database.WithTracing(tx)); err != nil {
 		return err
 	}
 
-	tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return WrapError(err)
+	}
+
+	for _, hook := range hooks.fns {
+		hook(ctx)
+	}
 
 	return nil
 }
+
+// AfterCommit calls fn after the transaction of ctx is committed, fn is never called if the transaction is rolled back.
+// It calls fn at once if ctx does not belong to a transaction of [PostgresClient.Transaction].
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+
+	fn(ctx)
+}
@@ -13,3 +13,9 @@ type Factory interface {
 	Connect(context.Context) error
 	Close(context.Context) error
 }
+
+// HealthChecker is implemented by factories that can report whether their dependency is usable (ex: ping a server),
+// it is optional and aggregated by readiness checks.
+type HealthChecker interface {
+	HealthCheck(context.Context) error
+}
@@ -0,0 +1,139 @@
+package redis_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/redis_client"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanCount is the hint of number of keys returned by a SCAN call.
+const scanCount = 512
+
+// redisCache is presentation of implementing redis cache of [cache.Cache], so the cached values are shared between replicas.
+type redisCache[K comparable, V any] struct {
+	client *redis_client.RedisClient
+	prefix string
+	ttl    time.Duration
+	codec  Codec
+}
+
+// NewRedisCache creates a redis cache which stores every value with the given ttl under "prefix:key",
+// so caches sharing the same redis do not collide. The client can be connected after creating the cache.
+func NewRedisCache[K comparable, V any](client *redis_client.RedisClient, prefix string, ttl time.Duration, codec Codec) cache.Cache[K, V] {
+	return &redisCache[K, V]{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+		codec:  codec,
+	}
+}
+
+// Add is implementation of Add by [redisCache] in [cache.Cache]
+func (c *redisCache[K, V]) Add(ctx context.Context, k K, v V) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("unable to encode value of %v: %w", k, err)
+	}
+
+	if err := c.client.Set(ctx, c.key(k), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("unable to add value to redis: %w", err)
+	}
+
+	return nil
+}
+
+// Get is implementation of Get by [redisCache] in [cache.Cache]
+func (c *redisCache[K, V]) Get(ctx context.Context, k K) (V, error) {
+	var v V
+	data, err := c.client.Get(ctx, c.key(k)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return v, fmt.Errorf("value of %v does not exists: %w", k, cache.ErrNotFound)
+		}
+		return v, fmt.Errorf("unable to get value from redis: %w", err)
+	}
+
+	if err := c.codec.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("unable to decode value of %v: %w", k, err)
+	}
+
+	return v, nil
+}
+
+// Remove is implementation of Remove by [redisCache] in [cache.Cache]
+func (c *redisCache[K, V]) Remove(ctx context.Context, k K) error {
+	removed, err := c.client.Del(ctx, c.key(k)).Result()
+	if err != nil {
+		return fmt.Errorf("unable to remove value from redis: %w", err)
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("unable to remove value of %v from redis: %w", k, cache.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Purge is implementation of Purge by [redisCache] in [cache.Purger], only keys of the prefix are removed.
+func (c *redisCache[K, V]) Purge(ctx context.Context) error {
+	// keys are removed after scanning, removing keys while scanning may move other keys behind the cursor.
+	var keys []string
+	if err := c.scan(ctx, func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += scanCount {
+		end := min(start+scanCount, len(keys))
+		if err := c.client.Del(ctx, keys[start:end]...).Err(); err != nil {
+			return fmt.Errorf("unable to remove keys of %s from redis: %w", c.prefix, err)
+		}
+	}
+
+	return nil
+}
+
+// Len is implementation of Len by [redisCache] in [cache.Sizer], it scans all keys of the prefix.
+func (c *redisCache[K, V]) Len(ctx context.Context) (int, error) {
+	var n int
+	err := c.scan(ctx, func(keys []string) error {
+		n += len(keys)
+		return nil
+	})
+
+	return n, err
+}
+
+// scan calls fn with batches of keys of the prefix, keys are scanned incrementally so redis is not blocked.
+func (c *redisCache[K, V]) scan(ctx context.Context, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.prefix+":*", scanCount).Result()
+		if err != nil {
+			return fmt.Errorf("unable to scan keys of %s from redis: %w", c.prefix, err)
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return fmt.Errorf("unable to handle keys of %s from redis: %w", c.prefix, err)
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (c *redisCache[K, V]) key(k K) string {
+	return fmt.Sprintf("%s:%v", c.prefix, k)
+}
@@ -0,0 +1,122 @@
+package redis_cache
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/redis_client"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID        int64
+	Name      sql.NullString
+	UpdatedAt time.Time
+}
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis_client.RedisClient) {
+	mr := miniredis.RunT(t)
+	client := redis_client.NewRedisClient(mr.Addr(), "", 0)
+	require.NoError(t, client.Connect(context.Background()))
+	t.Cleanup(func() { client.Close(context.Background()) })
+
+	return mr, client
+}
+
+func TestRedisCache(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSON, "msgpack": MsgPack} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			mr, client := newTestClient(t)
+			c := NewRedisCache[int64, *user](client, "user", time.Minute, codec)
+
+			want := &user{ID: 1, Name: sql.NullString{String: "Dat", Valid: true}, UpdatedAt: time.Date(2023, 11, 20, 10, 30, 0, 0, time.UTC)}
+			require.NoError(t, c.Add(ctx, 1, want))
+			assert.True(t, mr.Exists("user:1"))
+
+			got, err := c.Get(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, want.ID, got.ID)
+			assert.Equal(t, want.Name, got.Name)
+			assert.True(t, want.UpdatedAt.Equal(got.UpdatedAt))
+
+			require.NoError(t, c.Remove(ctx, 1))
+			_, err = c.Get(ctx, 1)
+			assert.ErrorIs(t, err, cache.ErrNotFound)
+			assert.ErrorIs(t, c.Remove(ctx, 1), cache.ErrNotFound)
+		})
+	}
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestClient(t)
+	c := NewRedisCache[string, time.Time](client, "revocation", time.Minute, MsgPack)
+
+	// zero time is a valid value, it must not be confused with a missing key.
+	require.NoError(t, c.Add(ctx, "token:1", time.Time{}))
+	got, err := c.Get(ctx, "token:1")
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+	assert.Equal(t, time.Minute, mr.TTL("revocation:token:1"))
+
+	mr.FastForward(time.Minute)
+	_, err = c.Get(ctx, "token:1")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+}
+
+func TestRedisCachePrefix(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestClient(t)
+	users := NewRedisCache[int64, string](client, "user", time.Minute, JSON)
+	accounts := NewRedisCache[int64, string](client, "account", time.Minute, JSON)
+
+	require.NoError(t, users.Add(ctx, 1, "user"))
+	require.NoError(t, accounts.Add(ctx, 1, "account"))
+
+	got, err := users.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "user", got)
+
+	got, err = accounts.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "account", got)
+}
+
+func TestRedisCachePurge(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestClient(t)
+	users := NewRedisCache[int64, string](client, "user", time.Minute, JSON)
+	accounts := NewRedisCache[int64, string](client, "account", time.Minute, JSON)
+
+	for i := int64(0); i < 1000; i++ {
+		require.NoError(t, users.Add(ctx, i, "user"))
+	}
+	require.NoError(t, accounts.Add(ctx, 1, "account"))
+
+	n, err := users.(cache.Sizer).Len(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, n)
+
+	// only keys of the prefix are removed.
+	require.NoError(t, users.(cache.Purger).Purge(ctx))
+	n, err = users.(cache.Sizer).Len(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.True(t, mr.Exists("account:1"))
+}
+
+func TestCodecByName(t *testing.T) {
+	codec, err := CodecByName("msgpack")
+	require.NoError(t, err)
+	assert.Equal(t, MsgPack, codec)
+
+	_, err = CodecByName("gob")
+	assert.Error(t, err)
+}
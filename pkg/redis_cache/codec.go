@@ -0,0 +1,53 @@
+package redis_cache
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes values to bytes stored in redis and decodes them back.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON is a [Codec] using [encoding/json], values are readable by other clients.
+	JSON Codec = jsonCodec{}
+	// MsgPack is a [Codec] using msgpack, values are smaller and faster to decode than json.
+	MsgPack Codec = msgpackCodec{}
+)
+
+// CodecByName returns the codec by name, "json" or "msgpack".
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "json":
+		return JSON, nil
+	case "msgpack":
+		return MsgPack, nil
+	default:
+		return nil, fmt.Errorf("codec %q is not supported", name)
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
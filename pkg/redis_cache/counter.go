@@ -0,0 +1,63 @@
+package redis_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user-management/pkg/cache"
+	"user-management/pkg/lockout"
+	"user-management/pkg/redis_client"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisCounter is presentation of implementing redis counter of [lockout.Counter], so failures are counted by all replicas.
+type redisCounter struct {
+	client *redis_client.RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCounter creates a counter which stores failures under "prefix:key", failures of a key expire ttl after its last failure.
+// The client can be connected after creating the counter.
+func NewRedisCounter(client *redis_client.RedisClient, prefix string, ttl time.Duration) lockout.Counter {
+	return &redisCounter{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// Incr is implementation of Incr by [redisCounter] in [lockout.Counter], INCR and EXPIRE are sent in a transaction
+// so a counter never lives without ttl.
+func (c *redisCounter) Incr(ctx context.Context, key string) (int64, error) {
+	var incr *redis.IntCmd
+	if _, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, c.key(key))
+		pipe.PExpire(ctx, c.key(key), c.ttl)
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("unable to increment counter in redis: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+// Remove is implementation of Remove by [redisCounter] in [lockout.Counter]
+func (c *redisCounter) Remove(ctx context.Context, key string) error {
+	removed, err := c.client.Del(ctx, c.key(key)).Result()
+	if err != nil {
+		return fmt.Errorf("unable to remove counter from redis: %w", err)
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("unable to remove counter of %s from redis: %w", key, cache.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (c *redisCounter) key(key string) string {
+	return fmt.Sprintf("%s:%s", c.prefix, key)
+}
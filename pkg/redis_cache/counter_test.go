@@ -0,0 +1,47 @@
+package redis_cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"user-management/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCounter(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestClient(t)
+	c := NewRedisCounter(client, "login_attempt", time.Minute)
+
+	// concurrent increments are all counted.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Incr(ctx, "user:dat")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	n, err := c.Incr(ctx, "user:dat")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), n)
+	assert.Equal(t, time.Minute, mr.TTL("login_attempt:user:dat"))
+
+	require.NoError(t, c.Remove(ctx, "user:dat"))
+	assert.ErrorIs(t, c.Remove(ctx, "user:dat"), cache.ErrNotFound)
+
+	// failures are forgotten after the ttl.
+	_, err = c.Incr(ctx, "user:dat")
+	require.NoError(t, err)
+	mr.FastForward(time.Minute)
+	n, err = c.Incr(ctx, "user:dat")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
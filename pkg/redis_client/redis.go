@@ -0,0 +1,59 @@
+package redis_client
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var errNotConnected = errors.New("redis is not connected")
+
+// RedisClient is presentation for a custom client of redis with [github.com/redis/go-redis/v9] based.
+type RedisClient struct {
+	*redis.Client
+	options *redis.Options
+}
+
+// NewRedisClient creates a new RedisClient using the given address, password and database number.
+func NewRedisClient(address, password string, db int) *RedisClient {
+	return &RedisClient{
+		options: &redis.Options{
+			Addr:     address,
+			Password: password,
+			DB:       db,
+		},
+	}
+}
+
+// Connect implements redis connection by [RedisClient].
+func (c *RedisClient) Connect(ctx context.Context) error {
+	c.Client = redis.NewClient(c.options)
+
+	if err := c.Client.Ping(ctx).Err(); err != nil {
+		return err
+	}
+
+	log.Println("connect redis successful")
+
+	return nil
+}
+
+// HealthCheck implements ping redis by [RedisClient] in [processor.HealthChecker].
+func (c *RedisClient) HealthCheck(ctx context.Context) error {
+	if c.Client == nil {
+		return errNotConnected
+	}
+
+	return c.Client.Ping(ctx).Err()
+}
+
+// Close implements close redis connection by [RedisClient].
+func (c *RedisClient) Close(ctx context.Context) error {
+	if c.Client == nil {
+		return nil
+	}
+
+	return c.Client.Close()
+}
@@ -26,6 +26,13 @@ func ConvertMapToStruct[T any](m map[string]any, s *T) error {
 				// TODO: convert other concrete types.
 			case reflect.TypeOf(value).Kind() == reflect.Float64 && field.Type.Kind() == reflect.Int64:
 				stValue.Field(i).Set(reflect.ValueOf(int64(value.(float64))))
+			// convert string to bool if the result struct defined bool (ex: ?purge=true).
+			case reflect.TypeOf(value).Kind() == reflect.String && field.Type.Kind() == reflect.Bool:
+				bVal, err := strconv.ParseBool(value.(string))
+				if err != nil {
+					return err
+				}
+				stValue.Field(i).Set(reflect.ValueOf(bVal))
 			default:
 				stValue.Field(i).Set(reflect.ValueOf(value))
 			}
@@ -48,6 +48,19 @@ func TestConvertMapToStruct(t *testing.T) {
 	}
 }
 
+func TestConvertMapToStructFromQuery(t *testing.T) {
+	type Request struct {
+		ID    int64 `json:"id"`
+		Purge bool  `json:"purge"`
+	}
+
+	var req Request
+	assert.NoError(t, ConvertMapToStruct(map[string]any{"id": "1", "purge": "true"}, &req))
+	assert.Equal(t, Request{ID: 1, Purge: true}, req)
+
+	assert.Error(t, ConvertMapToStruct(map[string]any{"purge": "yes"}, &req))
+}
+
 func TestCopyStruct(t *testing.T) {
 	type source struct {
 		A string
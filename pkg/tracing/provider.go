@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	// ExporterNone disables tracing, spans are recorded by the no-op tracer provider of otel.
+	ExporterNone = "none"
+	// ExporterStdout writes spans to stdout, it is useful for development.
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends spans to an otlp http receiver like the opentelemetry collector or jaeger.
+	ExporterOTLP = "otlp"
+)
+
+// Provider is presentation of the global tracer provider of otel, it implements [processor.Factory].
+type Provider struct {
+	serviceName  string
+	exporter     string
+	otlpEndpoint string
+
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider creates a provider exporting spans of serviceName by exporter,
+// otlpEndpoint is only used by [ExporterOTLP].
+func NewProvider(serviceName, exporter, otlpEndpoint string) (*Provider, error) {
+	switch exporter {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+	default:
+		return nil, fmt.Errorf("trace exporter %q is not supported", exporter)
+	}
+
+	return &Provider{
+		serviceName:  serviceName,
+		exporter:     exporter,
+		otlpEndpoint: otlpEndpoint,
+	}, nil
+}
+
+// Connect is implementation of Connect by [Provider] in [processor.Factory]
+// It sets the global tracer provider and the W3C trace context propagator of otel.
+func (p *Provider) Connect(ctx context.Context) error {
+	if p.exporter == ExporterNone {
+		return nil
+	}
+
+	exporter, err := p.newExporter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create %s trace exporter: %w", p.exporter, err)
+	}
+
+	p.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(p.serviceName),
+		)),
+	)
+	otel.SetTracerProvider(p.provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return nil
+}
+
+// Close is implementation of Close by [Provider] in [processor.Factory]
+// Spans waiting in batch are exported before closing.
+func (p *Provider) Close(ctx context.Context) error {
+	if p.provider == nil {
+		return nil
+	}
+
+	return p.provider.Shutdown(ctx)
+}
+
+func (p *Provider) newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if p.exporter == ExporterStdout {
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+
+	var opts []otlptracehttp.Option
+	// without endpoint, the exporter is configured by OTEL_EXPORTER_OTLP_* environment variables.
+	if p.otlpEndpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(p.otlpEndpoint))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
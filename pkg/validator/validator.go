@@ -0,0 +1,282 @@
+// Package validator provides declarative validation of struct fields by the "validate" tag.
+//
+// Rules are separated by comma and applied in order, for example:
+//
+//	UserName string `json:"user_name" validate:"required,min=3,max=64,regexp=^[a-z0-9_]+$"`
+//	Role     string `json:"role" validate:"required,enum=user_role"`
+//	Amount   int64  `json:"amount" validate:"gt=0"`
+//
+// Supported rules:
+//   - required: value must not be zero value.
+//   - min=N, max=N: length for strings and slices, value for numbers.
+//   - gt=N: number must be greater than N.
+//   - oneof=a b c: value must be one of space separated values.
+//   - enum=name: value must be one of values registered by [RegisterEnum].
+//   - regexp=pattern: string must match the pattern, it must be the last rule because pattern may contain comma.
+//
+// Except required, rules are skipped when the value is zero value, so optional fields only validated when provided.
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	tagName  = "validate"
+	nameTag  = "json"
+	ruleSep  = ","
+	paramSep = "="
+)
+
+var (
+	enums   sync.Map // map[string][]string
+	regexps sync.Map // map[string]*regexp.Regexp
+	checked sync.Map // map[reflect.Type]error
+)
+
+// rule is a parsed rule of a "validate" tag.
+type rule struct {
+	key   string
+	param string
+}
+
+// RegisterEnum registers list of values by name, which can be used by "enum=name" rule.
+// Enums must be registered before structs using them are checked by [Check].
+func RegisterEnum[T ~string](name string, values ...T) {
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		list = append(list, string(v))
+	}
+
+	enums.Store(name, list)
+}
+
+// Check returns an error if a "validate" tag of struct s (or pointer to struct) is malformed,
+// like an unknown rule, a param which is not a number, an unregistered enum or a pattern which does not compile.
+// It is called when a struct is registered to be validated, so malformed tags fail at startup instead of inside a request.
+func Check(s any) error {
+	t := reflect.TypeOf(s)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if err, ok := checked.Load(t); ok {
+		if err == nil {
+			return nil
+		}
+		return err.(error)
+	}
+
+	var err error
+	for i := 0; i < t.NumField() && err == nil; i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok || tag == "" || !field.IsExported() {
+			continue
+		}
+
+		for _, r := range parseRules(tag) {
+			if err = checkRule(field, r); err != nil {
+				break
+			}
+		}
+	}
+
+	checked.Store(t, err)
+
+	return err
+}
+
+// Validate returns all violations of fields in struct s (or pointer to struct) by their rules.
+// It panics if a tag of s is malformed, [Check] reports it without panicking.
+func Validate(s any) []string {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if err := Check(s); err != nil {
+		panic(err.Error())
+	}
+
+	var violations []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok || tag == "" || !field.IsExported() {
+			continue
+		}
+
+		if violation := validateField(fieldName(field), v.Field(i), parseRules(tag)); violation != "" {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// fieldName returns the json name of field used in violations.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get(nameTag), ruleSep)
+	if name == "" {
+		name = field.Name
+	}
+
+	return name
+}
+
+// parseRules returns rules of tag in order.
+func parseRules(tag string) []rule {
+	var rules []rule
+	for tag != "" {
+		var r string
+		// regexp pattern may contain comma, so it consumes the rest of tag.
+		if strings.HasPrefix(tag, "regexp"+paramSep) {
+			r, tag = tag, ""
+		} else {
+			r, tag, _ = strings.Cut(tag, ruleSep)
+		}
+
+		key, param, _ := strings.Cut(r, paramSep)
+		rules = append(rules, rule{key: key, param: param})
+	}
+
+	return rules
+}
+
+// checkRule returns an error if r can not be applied to field.
+func checkRule(field reflect.StructField, r rule) error {
+	switch r.key {
+	case "required":
+	case "min", "max", "gt":
+		if _, err := strconv.ParseFloat(r.param, 64); err != nil {
+			return fmt.Errorf("validator: param of %s rule for %s is not a number", r.key, field.Name)
+		}
+
+		if _, _, ok := measure(reflect.Zero(field.Type)); !ok {
+			return fmt.Errorf("validator: %s rule is not supported for %s", r.key, field.Name)
+		}
+	case "oneof":
+		if len(strings.Fields(r.param)) == 0 {
+			return fmt.Errorf("validator: oneof rule for %s has no values", field.Name)
+		}
+	case "enum":
+		if _, ok := enums.Load(r.param); !ok {
+			return fmt.Errorf("validator: enum %s for %s is not registered", r.param, field.Name)
+		}
+	case "regexp":
+		if field.Type.Kind() != reflect.String {
+			return fmt.Errorf("validator: regexp rule is not supported for %s", field.Name)
+		}
+
+		if _, err := compile(r.param); err != nil {
+			return fmt.Errorf("validator: pattern of regexp rule for %s is not valid: %w", field.Name, err)
+		}
+	default:
+		return fmt.Errorf("validator: unknown rule %s for %s", r.key, field.Name)
+	}
+
+	return nil
+}
+
+// validateField returns the first violation of value by rules, empty if value is valid.
+func validateField(name string, value reflect.Value, rules []rule) string {
+	for _, r := range rules {
+		if r.key == "required" {
+			if value.IsZero() {
+				return fmt.Sprintf("%s must not be empty", name)
+			}
+			continue
+		}
+
+		if value.IsZero() {
+			return ""
+		}
+
+		if violation := applyRule(name, value, r.key, r.param); violation != "" {
+			return violation
+		}
+	}
+
+	return ""
+}
+
+// applyRule returns the violation of value by a rule which was checked by [Check].
+func applyRule(name string, value reflect.Value, key, param string) string {
+	switch key {
+	case "min", "max", "gt":
+		limit, _ := strconv.ParseFloat(param, 64)
+		size, unit, _ := measure(value)
+
+		switch {
+		case key == "min" && size < limit:
+			return fmt.Sprintf("%s must be at least %s%s", name, param, unit)
+		case key == "max" && size > limit:
+			return fmt.Sprintf("%s must be at most %s%s", name, param, unit)
+		case key == "gt" && size <= limit:
+			return fmt.Sprintf("%s must be greater than %s", name, param)
+		}
+	case "oneof":
+		values := strings.Fields(param)
+		if !slices.Contains(values, fmt.Sprint(value.Interface())) {
+			return fmt.Sprintf("%s must be one of [%s]", name, strings.Join(values, ", "))
+		}
+	case "enum":
+		list, _ := enums.Load(param)
+		values := list.([]string)
+		if !slices.Contains(values, fmt.Sprint(value.Interface())) {
+			return fmt.Sprintf("%s must be one of [%s]", name, strings.Join(values, ", "))
+		}
+	case "regexp":
+		re, _ := compile(param)
+		if !re.MatchString(value.String()) {
+			return fmt.Sprintf("%s is not valid format", name)
+		}
+	}
+
+	return ""
+}
+
+// measure returns the size of value to compare with min, max and gt rules, with unit used in violation.
+func measure(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters", true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", true
+	default:
+		return 0, "", false
+	}
+}
+
+// compile returns the cached compiled regexp of pattern.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(pattern, re)
+
+	return re, nil
+}
@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type role string
+	RegisterEnum("test_role", role("ADMIN"), role("USER"))
+
+	type request struct {
+		ID       int64  `json:"id" validate:"required"`
+		UserName string `json:"user_name" validate:"required,min=3,max=8,regexp=^[a-z0-9_]{1,}$"`
+		Role     string `json:"role" validate:"required,enum=test_role"`
+		Amount   int64  `json:"amount" validate:"gt=0"`
+		Status   string `json:"status" validate:"oneof=ACTIVE FROZEN"`
+		Note     string `json:"note"`
+	}
+
+	tests := []struct {
+		name string
+		req  request
+		want []string
+	}{
+		{
+			name: "happy case",
+			req: request{
+				ID:       1,
+				UserName: "dat_197",
+				Role:     "USER",
+				Amount:   100,
+				Status:   "ACTIVE",
+			},
+		},
+		{
+			name: "optional fields are skipped when empty",
+			req: request{
+				ID:       1,
+				UserName: "dat",
+				Role:     "ADMIN",
+			},
+		},
+		{
+			name: "all violations are returned",
+			req: request{
+				UserName: "Dat Le",
+				Role:     "SUPER_ADMIN",
+				Amount:   -1,
+				Status:   "CLOSED",
+			},
+			want: []string{
+				"id must not be empty",
+				"user_name is not valid format",
+				"role must be one of [ADMIN, USER]",
+				"amount must be greater than 0",
+				"status must be one of [ACTIVE, FROZEN]",
+			},
+		},
+		{
+			name: "length of string",
+			req: request{
+				ID:       1,
+				UserName: "da",
+				Role:     "USER",
+			},
+			want: []string{
+				"user_name must be at least 3 characters",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Validate(&tt.req))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	RegisterEnum("test_status", "ACTIVE", "FROZEN")
+
+	tests := []struct {
+		name    string
+		req     any
+		wantErr string
+	}{
+		{
+			name: "valid tags",
+			req: &struct {
+				Name   string `json:"name" validate:"required,min=1,max=8,regexp=^[a-z]{1,8}$"`
+				Status string `json:"status" validate:"enum=test_status"`
+			}{},
+		},
+		{
+			name: "unknown rule",
+			req: &struct {
+				Name string `json:"name" validate:"required,len=3"`
+			}{},
+			wantErr: "validator: unknown rule len for Name",
+		},
+		{
+			name: "param is not a number",
+			req: &struct {
+				Amount int64 `json:"amount" validate:"gt=zero"`
+			}{},
+			wantErr: "validator: param of gt rule for Amount is not a number",
+		},
+		{
+			name: "rule is not supported by type",
+			req: &struct {
+				Enabled bool `json:"enabled" validate:"min=1"`
+			}{},
+			wantErr: "validator: min rule is not supported for Enabled",
+		},
+		{
+			name: "enum is not registered",
+			req: &struct {
+				Role string `json:"role" validate:"enum=unknown_role"`
+			}{},
+			wantErr: "validator: enum unknown_role for Role is not registered",
+		},
+		{
+			name: "pattern does not compile",
+			req: &struct {
+				Name string `json:"name" validate:"regexp=^[a-z$"`
+			}{},
+			wantErr: "validator: pattern of regexp rule for Name is not valid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Panics(t, func() { Validate(tt.req) })
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package xerrors provides typed domain errors, which are returned by services and repositories
+// and mapped to status codes by the transport layer.
+package xerrors
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Kind is the category of an error, each kind is mapped to one transport status code.
+type Kind uint8
+
+const (
+	KindInternal Kind = iota
+	KindInvalidArgument
+	KindNotFound
+	KindConflict
+	KindPermissionDenied
+	KindUnauthenticated
+	KindTooManyRequests
+)
+
+// String returns the default reason of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindInvalidArgument:
+		return "INVALID_ARGUMENT"
+	case KindNotFound:
+		return "NOT_FOUND"
+	case KindConflict:
+		return "CONFLICT"
+	case KindPermissionDenied:
+		return "PERMISSION_DENIED"
+	case KindUnauthenticated:
+		return "UNAUTHENTICATED"
+	case KindTooManyRequests:
+		return "TOO_MANY_REQUESTS"
+	default:
+		return "INTERNAL"
+	}
+}
+
+// Error is a representation of a domain error with a kind and a stable machine-readable reason.
+type Error struct {
+	kind    Kind
+	reason  string
+	details []string
+	// retryAfter is how long clients should wait before retrying, zero if unknown.
+	retryAfter time.Duration
+	err        error
+	// cause is the underlying error which is only logged, it is not a part of the message returned to clients.
+	cause error
+}
+
+// newError returns an [Error] of kind with message formatted like [fmt.Errorf], so "%w" can wrap a cause.
+func newError(kind Kind, format string, args ...any) *Error {
+	return &Error{
+		kind: kind,
+		err:  fmt.Errorf(format, args...),
+	}
+}
+
+// Internal returns an error of [KindInternal].
+func Internal(format string, args ...any) *Error {
+	return newError(KindInternal, format, args...)
+}
+
+// InvalidArgument returns an error of [KindInvalidArgument].
+func InvalidArgument(format string, args ...any) *Error {
+	return newError(KindInvalidArgument, format, args...)
+}
+
+// NotFound returns an error of [KindNotFound].
+func NotFound(format string, args ...any) *Error {
+	return newError(KindNotFound, format, args...)
+}
+
+// Conflict returns an error of [KindConflict].
+func Conflict(format string, args ...any) *Error {
+	return newError(KindConflict, format, args...)
+}
+
+// PermissionDenied returns an error of [KindPermissionDenied].
+func PermissionDenied(format string, args ...any) *Error {
+	return newError(KindPermissionDenied, format, args...)
+}
+
+// Unauthenticated returns an error of [KindUnauthenticated].
+func Unauthenticated(format string, args ...any) *Error {
+	return newError(KindUnauthenticated, format, args...)
+}
+
+// TooManyRequests returns an error of [KindTooManyRequests].
+func TooManyRequests(format string, args ...any) *Error {
+	return newError(KindTooManyRequests, format, args...)
+}
+
+// WithReason returns a copy of the error with a specific reason instead of the kind default.
+func (e *Error) WithReason(reason string) *Error {
+	cp := *e
+	cp.reason = reason
+
+	return &cp
+}
+
+// WithDetails returns a copy of the error with details, like violations of each field in a request.
+func (e *Error) WithDetails(details ...string) *Error {
+	cp := *e
+	cp.details = append(slices.Clip(e.details), details...)
+
+	return &cp
+}
+
+// WithRetryAfter returns a copy of the error telling clients to wait d before retrying.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	cp := *e
+	cp.retryAfter = d
+
+	return &cp
+}
+
+// WithCause returns a copy of the error keeping cause out of its message, so details of the cause
+// (ex: texts of a database driver) are only logged and never returned to clients.
+func (e *Error) WithCause(cause error) *Error {
+	cp := *e
+	cp.cause = cause
+
+	return &cp
+}
+
+// Kind returns the kind of the error.
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Reason returns the reason of the error, default is the name of kind.
+func (e *Error) Reason() string {
+	if e.reason == "" {
+		return e.kind.String()
+	}
+
+	return e.reason
+}
+
+// Details returns the details of the error.
+func (e *Error) Details() []string {
+	return e.details
+}
+
+// RetryAfter returns how long clients should wait before retrying, zero if unknown.
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped errors of the message and the cause, so both can be matched by [errors.Is] and [errors.As].
+func (e *Error) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.err}
+	}
+
+	return []error{e.err, e.cause}
+}
+
+// KindOf returns the kind of the first [Error] in the chain of err, untyped errors are [KindInternal].
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+
+	return KindInternal
+}
+
+// ReasonOf returns the reason of the first [Error] in the chain of err.
+func ReasonOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason()
+	}
+
+	return KindInternal.String()
+}
+
+// DetailsOf returns the details of the first [Error] in the chain of err.
+func DetailsOf(err error) []string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.details
+	}
+
+	return nil
+}
+
+// CauseOf returns the cause of the first [Error] in the chain of err, nil if there is no cause.
+func CauseOf(err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.cause
+	}
+
+	return nil
+}
+
+// IsKind returns true if the kind of err is k.
+func IsKind(err error, k Kind) bool {
+	return KindOf(err) == k
+}
+
+// RetryAfterOf returns how long clients should wait before retrying by the first [Error] in the chain of err.
+func RetryAfterOf(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.retryAfter
+	}
+
+	return 0
+}
@@ -0,0 +1,40 @@
+package xerrors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	err := NotFound("user does not exists: %w", sql.ErrNoRows).WithReason("USER_NOT_FOUND")
+	wrapped := fmt.Errorf("unable to retrieve user by id: %w", err)
+
+	assert.Equal(t, KindNotFound, KindOf(wrapped))
+	assert.Equal(t, "USER_NOT_FOUND", ReasonOf(wrapped))
+	assert.True(t, errors.Is(wrapped, sql.ErrNoRows))
+	assert.Equal(t, "unable to retrieve user by id: user does not exists: sql: no rows in result set", wrapped.Error())
+
+	assert.Equal(t, "CONFLICT", Conflict("username already exists").Reason())
+
+	untyped := errors.New("connection refused")
+	assert.Equal(t, KindInternal, KindOf(untyped))
+	assert.Equal(t, "INTERNAL", ReasonOf(untyped))
+	assert.Zero(t, RetryAfterOf(untyped))
+
+	throttled := fmt.Errorf("unable to login: %w", TooManyRequests("too many failed attempts").WithRetryAfter(time.Minute))
+	assert.Equal(t, KindTooManyRequests, KindOf(throttled))
+	assert.Equal(t, "TOO_MANY_REQUESTS", ReasonOf(throttled))
+	assert.Equal(t, time.Minute, RetryAfterOf(throttled))
+
+	cause := errors.New(`duplicate key value violates unique constraint "users_user_name_key"`)
+	conflict := fmt.Errorf("unable to create user: %w", Conflict("value already exists").WithCause(cause))
+	assert.Equal(t, "unable to create user: value already exists", conflict.Error())
+	assert.Equal(t, cause, CauseOf(conflict))
+	assert.True(t, errors.Is(conflict, cause))
+	assert.Nil(t, CauseOf(untyped))
+}